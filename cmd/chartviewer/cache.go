@@ -0,0 +1,85 @@
+package chartviewer
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/server/service"
+	"context"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type cacheOptions struct {
+	repoIndexTTL time.Duration
+	valuesTTL    time.Duration
+	templatesTTL time.Duration
+	manifestsTTL time.Duration
+}
+
+func (o *cacheOptions) addFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.repoIndexTTL, "cache-ttl-repo-index", time.Hour, "[Optional] How long the chart list and index of a repo are cached, 0 caches forever")
+	flags.DurationVar(&o.valuesTTL, "cache-ttl-values", 0, "[Optional] How long chart values are cached, 0 caches forever")
+	flags.DurationVar(&o.templatesTTL, "cache-ttl-templates", 0, "[Optional] How long chart templates are cached, 0 caches forever")
+	flags.DurationVar(&o.manifestsTTL, "cache-ttl-manifests", 0, "[Optional] How long rendered manifests are cached, 0 caches forever")
+}
+
+func (o *cacheOptions) ttl() service.CacheTTL {
+	return service.CacheTTL{
+		RepoIndex: o.repoIndexTTL,
+		Values:    o.valuesTTL,
+		Templates: o.templatesTTL,
+		Manifests: o.manifestsTTL,
+	}
+}
+
+func NewCacheCommand() *cobra.Command {
+	command := cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cached chart data",
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+
+	command.AddCommand(newCachePurgeCommand())
+
+	return &command
+}
+
+func newCachePurgeCommand() *cobra.Command {
+	var (
+		storage   storageOptions
+		repoName  string
+		chartName string
+	)
+
+	command := cobra.Command{
+		Use:     "purge",
+		Short:   "Delete cached chart data so it is fetched again on the next request",
+		Example: "chart-viewer cache purge --repo stable --chart nginx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := storage.newRepository()
+			if err != nil {
+				log.Printf("cannot connect to %s: %s\n", storage.String(), err)
+				return err
+			}
+
+			svc := service.NewService(helm.NewHelmClient(repo, 0), repo, nil, service.CacheTTL{})
+			deleted, err := svc.PurgeCache(context.Background(), repoName, chartName)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("%d cache entries deleted\n", deleted)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&repoName, "repo", "", "[Optional] Only purge the cache of this repo, every repo when empty")
+	command.Flags().StringVar(&chartName, "chart", "", "[Optional] Only purge the cache of this chart, requires --repo")
+	storage.addFlags(command.Flags())
+
+	return &command
+}
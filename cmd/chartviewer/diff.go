@@ -0,0 +1,121 @@
+package chartviewer
+
+import (
+	"chart-viewer/pkg/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func NewDiffCommand() *cobra.Command {
+	command := cobra.Command{
+		Use:   "diff",
+		Short: "Compare two versions of a chart",
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+
+	command.AddCommand(newDiffValuesCommand())
+
+	return &command
+}
+
+func newDiffValuesCommand() *cobra.Command {
+	var (
+		serverAddress string
+		fromVersion   string
+		toVersion     string
+		output        string
+	)
+
+	command := cobra.Command{
+		Use:     "values [repo] [chart]",
+		Short:   "List the default values that were added, removed, renamed or changed between two chart versions",
+		Example: "chart-viewer diff values stable nginx --from 0.1.0 --to 0.2.0 --server http://127.0.0.1:9999",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unknown output %q, use text or json", output)
+			}
+
+			query := url.Values{"from": {fromVersion}, "to": {toVersion}}
+			diffUrl := fmt.Sprintf("%s/api/v1/charts/diff/values/%s/%s?%s", strings.TrimSuffix(serverAddress, "/"),
+				url.PathEscape(args[0]), url.PathEscape(args[1]), query.Encode())
+
+			response, err := http.Get(diffUrl)
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				return err
+			}
+
+			if response.StatusCode != http.StatusOK {
+				return fmt.Errorf("diff failed with %s: %s", response.Status, body)
+			}
+
+			if output == "json" {
+				fmt.Println(string(body))
+				return nil
+			}
+
+			var valuesDiff model.ValuesDiffResponse
+			err = json.Unmarshal(body, &valuesDiff)
+			if err != nil {
+				return err
+			}
+
+			printValueChanges(os.Stdout, valuesDiff.Changes)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&serverAddress, "server", "http://127.0.0.1:9999", "[Optional] Address of the chart-viewer server")
+	command.Flags().StringVar(&fromVersion, "from", "", "[Required] Chart version to compare from")
+	command.Flags().StringVar(&toVersion, "to", "", "[Required] Chart version to compare to")
+	command.Flags().StringVarP(&output, "output", "o", "text", "[Optional] Output format, text or json")
+	command.MarkFlagRequired("from")
+	command.MarkFlagRequired("to")
+
+	return &command
+}
+
+func printValueChanges(w io.Writer, changes []model.ValueChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "no changes in the default values")
+		return
+	}
+
+	for _, change := range changes {
+		switch change.Status {
+		case model.DiffAdded:
+			fmt.Fprintf(w, "+ %s: %s\n", change.Path, formatValue(change.New))
+		case model.DiffRemoved:
+			fmt.Fprintf(w, "- %s: %s\n", change.Path, formatValue(change.Old))
+		case model.DiffRenamed:
+			fmt.Fprintf(w, "> %s -> %s: %s\n", change.OldPath, change.Path, formatValue(change.New))
+		default:
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", change.Path, formatValue(change.Old), formatValue(change.New))
+		}
+	}
+}
+
+func formatValue(value interface{}) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(formatted)
+}
@@ -0,0 +1,136 @@
+package chartviewer
+
+import (
+	"bytes"
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/kubeapi"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/server/service"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+func NewKubeVersionsCommand() *cobra.Command {
+	command := cobra.Command{
+		Use:   "kube-versions",
+		Short: "Manage the Kubernetes API data charts are analyzed against",
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+
+	command.AddCommand(newKubeVersionsImportCommand())
+
+	return &command
+}
+
+func newKubeVersionsImportCommand() *cobra.Command {
+	var (
+		storage storageOptions
+		name    string
+		release string
+	)
+
+	command := cobra.Command{
+		Use:   "import [file...]",
+		Short: "Add or replace kube versions from JSON files like api_versions.json, or from the API dumps of a cluster",
+		Long: `Add or replace kube versions from JSON files like api_versions.json.
+
+With --name the files are the API dumps of a cluster, the output of kubectl api-versions or the OpenAPI v2 or v3 specs
+it serves, merged into a single kube version with that name. CRD groups are included, and OpenAPI specs also list the
+kinds of every group. The release of the cluster is read from an OpenAPI v2 spec, --release sets it otherwise.`,
+		Example: `chart-viewer kube-versions import ./api_versions.json --storage bolt
+kubectl api-versions > api-versions.txt && chart-viewer kube-versions import --name prod-cluster-1.24 --release 1.24 ./api-versions.txt
+kubectl get --raw /openapi/v2 > openapi.json && chart-viewer kube-versions import --name prod-cluster-1.24 ./openapi.json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := storage.newRepository()
+			if err != nil {
+				log.Printf("cannot connect to %s: %s\n", storage.String(), err)
+				return err
+			}
+
+			svc := service.NewService(helm.NewHelmClient(repo, 0), repo, nil, service.CacheTTL{})
+			if name != "" {
+				return importClusterDumps(svc, name, release, args)
+			}
+
+			for _, path := range args {
+				err = importKubeVersions(svc, path)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&name, "name", "", "[Optional] Import the files as the API dumps of a cluster, under this kube version name")
+	command.Flags().StringVar(&release, "release", "", "[Optional] Kubernetes release of the cluster like 1.24, used with --name")
+	storage.addFlags(command.Flags())
+
+	return &command
+}
+
+// importClusterDumps merges the API dumps of a cluster into the kube version name and stores it.
+func importClusterDumps(svc service.Service, name, release string, paths []string) error {
+	var dumps [][]byte
+	for _, path := range paths {
+		dump, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dumps = append(dumps, dump)
+	}
+
+	kubeVersion, err := kubeapi.Parse(name, dumps...)
+	if err != nil {
+		return err
+	}
+
+	if release != "" {
+		kubeVersion.Release = release
+	}
+
+	err = svc.ImportKubeVersions(context.Background(), []model.KubernetesAPIVersion{kubeVersion})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("kube version %s imported with %d api versions\n", name, len(kubeVersion.APIVersions))
+	return nil
+}
+
+// importKubeVersions reads a JSON array of kube versions, or a single one, and stores them.
+func importKubeVersions(svc service.Service, path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var kubeVersions []model.KubernetesAPIVersion
+	if trimmed := bytes.TrimSpace(content); len(trimmed) != 0 && trimmed[0] == '{' {
+		var kubeVersion model.KubernetesAPIVersion
+		err = json.Unmarshal(content, &kubeVersion)
+		kubeVersions = append(kubeVersions, kubeVersion)
+	} else {
+		err = json.Unmarshal(content, &kubeVersions)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	err = svc.ImportKubeVersions(context.Background(), kubeVersions)
+	if err != nil {
+		return fmt.Errorf("importing %s: %w", path, err)
+	}
+
+	log.Printf("%d kube versions imported from %s\n", len(kubeVersions), path)
+	return nil
+}
@@ -0,0 +1,135 @@
+package chartviewer
+
+import (
+	"bytes"
+	"chart-viewer/pkg/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var severityRanks = map[string]int{model.SeverityLow: 1, model.SeverityMedium: 2, model.SeverityHigh: 3}
+
+func NewPoliciesCommand() *cobra.Command {
+	command := cobra.Command{
+		Use:   "policies",
+		Short: "Check rendered charts against security and best practice policies",
+		Run: func(c *cobra.Command, args []string) {
+			c.HelpFunc()(c, args)
+		},
+	}
+
+	command.AddCommand(newPoliciesCheckCommand())
+
+	return &command
+}
+
+func newPoliciesCheckCommand() *cobra.Command {
+	var (
+		serverAddress string
+		valuesFiles   []string
+		set           []string
+		output        string
+		failOn        string
+	)
+
+	command := cobra.Command{
+		Use:     "check [repo] [chart] [version]",
+		Short:   "Render a chart version and list the resources violating a policy rule",
+		Example: "chart-viewer policies check stable nginx 0.1.0 --values ./prod.yaml --fail-on high --server http://127.0.0.1:9999",
+		Args:    cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unknown output %q, use text or json", output)
+			}
+
+			if _, ok := severityRanks[failOn]; failOn != "" && !ok {
+				return fmt.Errorf("unknown severity %q, use high, medium or low", failOn)
+			}
+
+			renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Set: set}}
+			for _, path := range valuesFiles {
+				values, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				renderOptions.Values = append(renderOptions.Values, string(values))
+			}
+
+			requestBody, err := json.Marshal(renderOptions)
+			if err != nil {
+				return err
+			}
+
+			policiesUrl := fmt.Sprintf("%s/api/v1/charts/policies/%s/%s/%s", strings.TrimSuffix(serverAddress, "/"),
+				url.PathEscape(args[0]), url.PathEscape(args[1]), url.PathEscape(args[2]))
+
+			response, err := http.Post(policiesUrl, "application/json", bytes.NewReader(requestBody))
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				return err
+			}
+
+			if response.StatusCode != http.StatusOK {
+				return fmt.Errorf("policy check failed with %s: %s", response.Status, body)
+			}
+
+			var report model.PolicyCheckResponse
+			err = json.Unmarshal(body, &report)
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				fmt.Println(string(body))
+			} else {
+				printPolicyFindings(os.Stdout, report.Findings)
+			}
+
+			failing := 0
+			for _, finding := range report.Findings {
+				if failOn != "" && severityRanks[finding.Severity] >= severityRanks[failOn] {
+					failing++
+				}
+			}
+			if failing != 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d findings of severity %s or higher", failing, failOn)
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&serverAddress, "server", "http://127.0.0.1:9999", "[Optional] Address of the chart-viewer server")
+	command.Flags().StringArrayVarP(&valuesFiles, "values", "f", nil, "[Optional] Values file to render the chart with, can be repeated")
+	command.Flags().StringArrayVar(&set, "set", nil, "[Optional] Value to set like key=value, can be repeated")
+	command.Flags().StringVarP(&output, "output", "o", "text", "[Optional] Output format, text or json")
+	command.Flags().StringVar(&failOn, "fail-on", "", "[Optional] Exit with an error on findings of this severity or higher, high, medium or low")
+
+	return &command
+}
+
+func printPolicyFindings(w io.Writer, findings []model.PolicyFinding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(w, "no policy findings")
+		return
+	}
+
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%-6s %s %s: %s (%s at %s)\n", strings.ToUpper(finding.Severity), finding.Manifest,
+			finding.Resource, finding.Message, finding.Rule, finding.Path)
+	}
+}
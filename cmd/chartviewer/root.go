@@ -16,6 +16,11 @@ func NewRootCommand() *cobra.Command {
 	command.AddCommand(
 		NewServeCommand(),
 		NewSeedCommand(),
+		NewCacheCommand(),
+		NewUploadCommand(),
+		NewDiffCommand(),
+		NewKubeVersionsCommand(),
+		NewPoliciesCommand(),
 	)
 
 	return command
@@ -3,11 +3,14 @@ package chartviewer
 import (
 	"chart-viewer/pkg/helm"
 	"chart-viewer/pkg/model"
-	"chart-viewer/pkg/repository"
 	"chart-viewer/pkg/server/service"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strings"
 	"sync"
 
 	"github.com/spf13/cobra"
@@ -17,81 +20,122 @@ var wg = &sync.WaitGroup{}
 
 func NewSeedCommand() *cobra.Command {
 	var (
-		redisHost          string
-		redisPort          string
-		repoSeedPath       string
-		apiVersionSeedPath string
+		storage             storageOptions
+		cache               cacheOptions
+		repoSeedPath        string
+		apiVersionSeedPath  string
+		deprecationSeedPath string
 	)
 
 	command := cobra.Command{
 		Use:     "seed",
-		Short:   "Seed the redis with chart info",
-		Example: "chart-viewer seed --redis-host 127.0.0.1 --redis-port 6379 --seed-file ./seed.json",
+		Short:   "Seed the storage with chart info",
+		Example: "chart-viewer seed --redis-host 127.0.0.1 --redis-port 6379 --repo-seed ./seed.json",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			redisAddress := fmt.Sprintf("%s:%s", redisHost, redisPort)
-
-			repo, err := repository.NewRepository(redisAddress)
+			repo, err := storage.newRepository()
 			if err != nil {
-				log.Printf("cannot connect to redis: %s\n", err)
+				log.Printf("cannot connect to %s: %s\n", storage.String(), err)
 				return err
 			}
 
-			log.Printf("connected to redis on %s:%s\n", redisHost, redisPort)
-			log.Println("starting to populate redis...")
+			log.Printf("connected to %s\n", storage.String())
+			log.Println("starting to populate storage...")
+
+			h := helm.NewHelmClient(repo, cache.ttl().RepoIndex)
+			svc := service.NewService(h, repo, nil, cache.ttl())
 
-			err = seedKubeVersion(repo, apiVersionSeedPath)
+			_, err = svc.DeleteLegacyCache(context.Background())
+			if err != nil {
+				log.Printf("failed to delete legacy cache entries: %s\n", err)
+			}
+
+			err = importKubeVersions(svc, apiVersionSeedPath)
 			if err != nil {
 				log.Printf("failed to seed api version: %s\n", err)
 			}
 			log.Println("Kubernetes API version seeded")
 
-			err = seedRepo(repo, repoSeedPath)
+			err = seedAPIDeprecations(svc, deprecationSeedPath)
 			if err != nil {
-				log.Printf("failed to seed chart repository: %s\n", err)
-				return err
+				log.Printf("failed to seed api deprecations: %s\n", err)
+			}
+			log.Println("Kubernetes API deprecations seeded")
+
+			// the charts of the repos that were stored are pulled even when others failed
+			seedErr := seedRepo(svc, repoSeedPath)
+			if seedErr != nil {
+				log.Printf("failed to seed chart repository: %s\n", seedErr)
 			}
-			seedChart(repo)
+			seedChart(svc)
 			wg.Wait()
 
-			return nil
+			return seedErr
 		},
 	}
 
-	command.Flags().StringVar(&redisHost, "redis-host", "127.0.0.1", "Redis host address")
-	command.Flags().StringVar(&redisPort, "redis-port", "6379", "Redis host port")
+	storage.addFlags(command.Flags())
+	cache.addFlags(command.Flags())
 	command.Flags().StringVar(&repoSeedPath, "repo-seed", "./seed.json", "Path to JSON file that contain array of repositories.")
 	command.Flags().StringVar(&apiVersionSeedPath, "kube-version-seed", "./api_versions.json", "Path to JSON file that contain list of Kubernetes API version for each Kubernetes version")
+	command.Flags().StringVar(&deprecationSeedPath, "deprecation-seed", "./api_deprecations.json", "Path to JSON file that contain the deprecated and removed Kubernetes API versions of each kind")
 	return &command
 }
 
-func seedKubeVersion(repo repository.Repository, path string) error {
-	apiVersions, err := ioutil.ReadFile(path)
+func seedAPIDeprecations(svc service.Service, path string) error {
+	content, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
 	}
 
-	stringifiedApiVersion := string(apiVersions)
-	repo.Set("api-versions", stringifiedApiVersion)
-	return nil
+	var deprecations []model.APIDeprecation
+	err = json.Unmarshal(content, &deprecations)
+	if err != nil {
+		return err
+	}
+
+	return svc.ImportAPIDeprecations(context.Background(), deprecations)
 }
 
-func seedRepo(repo repository.Repository, seedPath string) error {
-	repos, err := ioutil.ReadFile(seedPath)
+func seedRepo(svc service.Service, seedPath string) error {
+	content, err := ioutil.ReadFile(seedPath)
+	if err != nil {
+		return err
+	}
+
+	var repos []model.Repo
+	err = json.Unmarshal(content, &repos)
 	if err != nil {
 		return err
 	}
 
 	log.Printf("populating reposistories from %s\n", seedPath)
-	stringifiedRepos := string(repos)
-	repo.Set("repos", stringifiedRepos)
+	ctx := context.Background()
+	var failed []string
+	for _, repo := range repos {
+		err = svc.AddRepo(ctx, repo)
+		if errors.Is(err, service.ErrRepoExists) {
+			err = svc.UpdateRepo(ctx, repo)
+		}
+
+		if err != nil {
+			log.Printf("failed to seed repository %s: %s\n", repo.Name, err)
+			failed = append(failed, repo.Name)
+		}
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("cannot seed repositories %s", strings.Join(failed, ", "))
+	}
+
 	return nil
 }
 
-func seedChart(repo repository.Repository) {
-	h := helm.NewHelmClient(repo)
-	svc := service.NewService(h, repo, nil)
-
-	chartRepos := svc.GetRepos()
+func seedChart(svc service.Service) {
+	chartRepos, err := svc.GetRepos(context.Background())
+	if err != nil {
+		log.Printf("failed to get chart repositories: %s\n", err)
+		return
+	}
 
 	for _, repo := range chartRepos {
 		wg.Add(1)
@@ -101,7 +145,8 @@ func seedChart(repo repository.Repository) {
 
 func pullChart(svc service.Service, repo model.Repo) {
 	defer wg.Done()
-	err, charts := svc.GetCharts(repo.Name)
+	ctx := context.Background()
+	err, charts := svc.GetCharts(ctx, repo.Name)
 	if err != nil {
 		log.Printf("error populating charts from repo %s: %s", repo.Name, err)
 		return
@@ -111,7 +156,7 @@ func pullChart(svc service.Service, repo model.Repo) {
 		versions := chart.Versions
 		for _, version := range versions {
 			log.Printf("populating %s/%s:%s\n", repo.Name, chart.Name, version)
-			err, _ := svc.GetChart(repo.Name, chart.Name, version)
+			err, _ := svc.GetChart(ctx, repo.Name, chart.Name, version)
 
 			if err != nil {
 				log.Printf("error populating charts %s: %s", repo.Name, err)
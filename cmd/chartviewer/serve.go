@@ -3,12 +3,15 @@ package chartviewer
 import (
 	"chart-viewer/pkg/analyzer"
 	"chart-viewer/pkg/helm"
-	"chart-viewer/pkg/repository"
 	"chart-viewer/pkg/server/handler"
 	"chart-viewer/pkg/server/service"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/cobra"
@@ -16,10 +19,14 @@ import (
 
 func NewServeCommand() *cobra.Command {
 	var (
-		defaultHost      string
-		defaultPort      string
-		defaultRedisHost string
-		defaultRedisPort string
+		defaultHost         string
+		defaultPort         string
+		storage             storageOptions
+		cache               cacheOptions
+		repoSeedPath        string
+		apiVersionSeedPath  string
+		deprecationSeedPath string
+		policyDir           string
 	)
 
 	command := cobra.Command{
@@ -29,21 +36,51 @@ func NewServeCommand() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			host := defaultHost
 			port := defaultPort
-			redisHost := defaultRedisHost
-			redisPort := defaultRedisPort
 
-			redisAddress := fmt.Sprintf("%s:%s", redisHost, redisPort)
 			address := fmt.Sprintf("%s:%s", host, port)
 
-			repo, err := repository.NewRepository(redisAddress)
+			repo, err := storage.newRepository()
 			if err != nil {
-				fmt.Printf("cannot connect to redis: %s\n", err)
+				fmt.Printf("cannot connect to %s: %s\n", storage.String(), err)
 				return
 			}
 
-			helmClient := helm.NewHelmClient(repo)
-			analyser := analyzer.New()
-			svc := service.NewService(helmClient, repo, analyser)
+			ruleSet, err := analyzer.NewRuleSet(policyDir)
+			if err != nil {
+				fmt.Printf("cannot load policy rules: %s\n", err)
+				return
+			}
+			reloadRulesOnHangup(ruleSet)
+
+			helmClient := helm.NewHelmClient(repo, cache.ttl().RepoIndex)
+			analyser := analyzer.NewWithRules(ruleSet)
+			svc := service.NewService(helmClient, repo, analyser, cache.ttl())
+
+			_, err = svc.DeleteLegacyCache(context.Background())
+			if err != nil {
+				log.Printf("failed to delete legacy cache entries: %s\n", err)
+			}
+
+			if apiVersionSeedPath != "" {
+				err = importKubeVersions(svc, apiVersionSeedPath)
+				if err != nil {
+					log.Printf("failed to seed api version: %s\n", err)
+				}
+			}
+
+			if deprecationSeedPath != "" {
+				err = seedAPIDeprecations(svc, deprecationSeedPath)
+				if err != nil {
+					log.Printf("failed to seed api deprecations: %s\n", err)
+				}
+			}
+
+			if repoSeedPath != "" {
+				err = seedRepo(svc, repoSeedPath)
+				if err != nil {
+					log.Printf("failed to seed chart repository: %s\n", err)
+				}
+			}
 			r := createRouter(svc)
 
 			log.Printf("server run on http://%s\n", address)
@@ -53,12 +90,33 @@ func NewServeCommand() *cobra.Command {
 
 	command.Flags().StringVar(&defaultHost, "host", "0.0.0.0", "[Optional] App host address")
 	command.Flags().StringVar(&defaultPort, "port", "9999", "[Optional] App host port")
-	command.Flags().StringVar(&defaultRedisHost, "redis-host", "127.0.0.1", "[Optional] Redis host address")
-	command.Flags().StringVar(&defaultRedisPort, "redis-port", "6379", "[Optional] Redis host port")
+	command.Flags().StringVar(&repoSeedPath, "repo-seed", "", "[Optional] Path to JSON file of repositories to load on startup, useful with memory storage")
+	command.Flags().StringVar(&apiVersionSeedPath, "kube-version-seed", "", "[Optional] Path to JSON file of Kubernetes API versions to load on startup, useful with memory storage")
+	command.Flags().StringVar(&deprecationSeedPath, "deprecation-seed", "", "[Optional] Path to JSON file of deprecated Kubernetes APIs to load on startup, useful with memory storage")
+	command.Flags().StringVar(&policyDir, "policy-dir", "", "[Optional] Directory of YAML files of user-defined policy rules, reloaded on SIGHUP")
+	storage.addFlags(command.Flags())
+	cache.addFlags(command.Flags())
 
 	return &command
 }
 
+// reloadRulesOnHangup reloads the policy rules on SIGHUP, the rules in use are kept when a file is invalid.
+func reloadRulesOnHangup(ruleSet *analyzer.RuleSet) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		for range hangup {
+			err := ruleSet.Reload()
+			if err != nil {
+				log.Printf("failed to reload policy rules: %s\n", err)
+				continue
+			}
+			log.Printf("%d policy rules loaded\n", len(ruleSet.Rules()))
+		}
+	}()
+}
+
 func createRouter(svc service.Service) *mux.Router {
 	r := mux.NewRouter()
 
@@ -68,12 +126,36 @@ func createRouter(svc service.Service) *mux.Router {
 	apiV1 := r.PathPrefix("/api/v1/").Subrouter()
 	apiV1.Use(appHandler.LoggerMiddleware)
 	apiV1.HandleFunc("/repos", appHandler.GetReposHandler).Methods("GET")
+	apiV1.HandleFunc("/repos", appHandler.AddRepoHandler).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/repos/{repo-name}", appHandler.GetRepoHandler).Methods("GET")
+	apiV1.HandleFunc("/repos/{repo-name}", appHandler.UpdateRepoHandler).Methods("PUT", "OPTIONS")
+	apiV1.HandleFunc("/repos/{repo-name}", appHandler.DeleteRepoHandler).Methods("DELETE", "OPTIONS")
+	apiV1.HandleFunc("/kube-versions", appHandler.GetKubeVersionsHandler).Methods("GET")
+	apiV1.HandleFunc("/kube-versions", appHandler.AddKubeVersionHandler).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/kube-versions/{kube-version}", appHandler.GetKubeVersionHandler).Methods("GET")
+	apiV1.HandleFunc("/kube-versions/{kube-version}", appHandler.UpdateKubeVersionHandler).Methods("PUT", "OPTIONS")
+	apiV1.HandleFunc("/kube-versions/{kube-version}", appHandler.DeleteKubeVersionHandler).Methods("DELETE", "OPTIONS")
+	apiV1.HandleFunc("/charts/upload", appHandler.UploadChartHandler).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/diff/templates/{repo-name}/{chart-name}", appHandler.DiffTemplatesHandler).Methods("GET")
+	apiV1.HandleFunc("/charts/diff/values/{repo-name}/{chart-name}", appHandler.DiffValuesHandler).Methods("GET")
+	apiV1.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifestsHandler).Methods("GET", "POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/diff/rbac/{repo-name}/{chart-name}", appHandler.DiffRBACHandler).Methods("GET", "POST", "OPTIONS")
 	apiV1.HandleFunc("/charts/{repo-name}", appHandler.GetChartsHandler).Methods("GET")
 	apiV1.HandleFunc("/charts/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartHandler).Methods("GET")
 	apiV1.HandleFunc("/charts/values/{repo-name}/{chart-name}/{chart-version}", appHandler.GetValuesHandler).Methods("GET")
 	apiV1.HandleFunc("/charts/templates/{repo-name}/{chart-name}/{chart-version}", appHandler.GetTemplatesHandler).Methods("GET")
+	apiV1.HandleFunc("/charts/metadata/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartMetadataHandler).Methods("GET")
+	apiV1.HandleFunc("/charts/schema/{repo-name}/{chart-name}/{chart-version}", appHandler.GetValuesSchemaHandler).Methods("GET")
+	apiV1.HandleFunc("/charts/schema/validate/{repo-name}/{chart-name}/{chart-version}", appHandler.ValidateValuesHandler).Methods("POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifestsHandler).Methods("GET", "POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/policies/{repo-name}/{chart-name}/{chart-version}", appHandler.CheckPoliciesHandler).Methods("GET", "POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/images/{repo-name}/{chart-name}/{chart-version}", appHandler.GetImagesHandler).Methods("GET", "POST", "OPTIONS")
+	apiV1.HandleFunc("/charts/rbac/{repo-name}/{chart-name}/{chart-version}", appHandler.GetRBACReportHandler).Methods("GET", "POST", "OPTIONS")
 	apiV1.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifestsHandler).Methods("POST", "OPTIONS")
 	apiV1.HandleFunc("/charts/manifests/{repo-name}/{chart-name}/{chart-version}/{hash}", appHandler.GetManifestsHandler).Methods("GET")
+	apiV1.HandleFunc("/policies/rules", appHandler.GetPolicyRulesHandler).Methods("GET")
+	apiV1.HandleFunc("/admin/cache", appHandler.PurgeCacheHandler).Methods("DELETE", "OPTIONS")
+	apiV1.HandleFunc("/admin/policies/reload", appHandler.ReloadPolicyRulesHandler).Methods("POST", "OPTIONS")
 
 	fileServer := http.FileServer(http.Dir("ui/dist"))
 	r.PathPrefix("/js").Handler(http.StripPrefix("/", fileServer))
@@ -0,0 +1,41 @@
+package chartviewer
+
+import (
+	"chart-viewer/pkg/repository"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+type storageOptions struct {
+	storage   string
+	redisHost string
+	redisPort string
+	boltPath  string
+}
+
+func (o *storageOptions) addFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.storage, "storage", repository.StorageRedis, "[Optional] Storage backend, one of redis, memory or bolt")
+	flags.StringVar(&o.redisHost, "redis-host", "127.0.0.1", "[Optional] Redis host address")
+	flags.StringVar(&o.redisPort, "redis-port", "6379", "[Optional] Redis host port")
+	flags.StringVar(&o.boltPath, "bolt-path", "./chart-viewer.db", "[Optional] Path to the BoltDB file, used by bolt storage")
+}
+
+func (o *storageOptions) newRepository() (repository.Repository, error) {
+	return repository.New(repository.Config{
+		Storage:      o.storage,
+		RedisAddress: fmt.Sprintf("%s:%s", o.redisHost, o.redisPort),
+		BoltPath:     o.boltPath,
+	})
+}
+
+func (o *storageOptions) String() string {
+	switch o.storage {
+	case repository.StorageMemory:
+		return "memory storage"
+	case repository.StorageBolt:
+		return fmt.Sprintf("bolt storage on %s", o.boltPath)
+	default:
+		return fmt.Sprintf("redis on %s:%s", o.redisHost, o.redisPort)
+	}
+}
@@ -0,0 +1,86 @@
+package chartviewer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func NewUploadCommand() *cobra.Command {
+	var serverAddress string
+
+	command := cobra.Command{
+		Use:     "upload [chart directory or archive]",
+		Short:   "Upload a local chart to the server so it can be inspected before it is published",
+		Example: "chart-viewer upload ./charts/my-app --server http://127.0.0.1:9999",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			archive, err := readChartArchive(args[0])
+			if err != nil {
+				return err
+			}
+
+			uploadUrl := strings.TrimSuffix(serverAddress, "/") + "/api/v1/charts/upload"
+			response, err := http.Post(uploadUrl, "application/gzip", bytes.NewReader(archive))
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+
+			body, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				return err
+			}
+
+			if response.StatusCode != http.StatusCreated {
+				return fmt.Errorf("upload failed with %s: %s", response.Status, body)
+			}
+
+			fmt.Println(string(body))
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&serverAddress, "server", "http://127.0.0.1:9999", "[Optional] Address of the chart-viewer server")
+
+	return &command
+}
+
+// readChartArchive returns the archive as is, or packages the chart when path is a directory.
+func readChartArchive(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return ioutil.ReadFile(path)
+	}
+
+	c, err := loader.LoadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, err := ioutil.TempDir("", "chart-viewer-upload")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	archivePath, err := chartutil.Save(c, tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("packaged %s as %s\n", path, archivePath)
+	return ioutil.ReadFile(archivePath)
+}
@@ -1,8 +1,13 @@
 package main
 
-import "chart-viewer/cmd/chartviewer"
+import (
+	"chart-viewer/cmd/chartviewer"
+	"os"
+)
 
 func main() {
 	cmd := chartviewer.NewRootCommand()
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -4,10 +4,16 @@ go 1.16
 
 require (
 	github.com/go-redis/redis v6.15.8+incompatible
+	github.com/google/cel-go v0.10.1
 	github.com/gorilla/mux v1.7.4
 	github.com/kinbiko/jsonassert v1.0.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.0.0
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.6.1
+	github.com/xeipuuv/gojsonschema v1.1.0
+	go.etcd.io/bbolt v1.3.6
+	google.golang.org/genproto v0.0.0-20210831024726-fe130286e0e2
 	gopkg.in/yaml.v2 v2.3.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 	helm.sh/helm/v3 v3.2.4
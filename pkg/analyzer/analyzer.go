@@ -3,21 +3,44 @@ package analyzer
 import (
 	"chart-viewer/pkg/model"
 	"fmt"
+	"regexp"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
-type analytic struct{}
+type analytic struct {
+	rules *RuleSet
+}
+
+var (
+	documentSeparatorRegex = regexp.MustCompile(`(?m)^---.*$`)
+	apiVersionRegex        = regexp.MustCompile(`(?m)^apiVersion:[ \t]*([^#\n]*)`)
+	kindRegex              = regexp.MustCompile(`(?m)^kind:[ \t]*([^#\n]*)`)
+)
 
 type Analytic interface {
 	Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error)
+	AnalyzeSubcharts(subcharts []model.Subchart, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error)
+	AnalyzeManifests(manifests []model.Manifest, kubeAPIVersions model.KubernetesAPIVersion) ([]model.ResourceAnalysis, error)
+	FindDeprecations(manifests []model.Manifest, deprecations []model.APIDeprecation, kubeVersion string) ([]model.DeprecationFinding, error)
+	CheckPolicies(manifests []model.Manifest) ([]model.PolicyFinding, error)
+	PolicyRules() []model.PolicyRule
+	ReloadPolicyRules() error
+	ListImages(manifests []model.Manifest) ([]model.ContainerImage, error)
+	AnalyzeRBAC(manifests []model.Manifest, namespace string) (model.RBACReport, error)
 }
 
+// New returns an analyzer checking the built-in policy rules only.
 func New() Analytic {
-	return analytic{}
+	return analytic{rules: &RuleSet{rules: BuiltinRules()}}
+}
+
+// NewWithRules returns an analyzer checking the policy rules of the rule set.
+func NewWithRules(rules *RuleSet) Analytic {
+	return analytic{rules: rules}
 }
 
+// Analyze scans the raw template sources for the top level apiVersion and kind of each document, a template is
+// compatible when all of them are served. Templated apiVersions are only known once rendered, see AnalyzeManifests.
 func (a analytic) Analyze(templates []model.Template, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error) {
 	var results []model.AnalyticsResult
 
@@ -30,51 +53,103 @@ func (a analytic) Analyze(templates []model.Template, kubeAPIVersions model.Kube
 			Compatible: true,
 		}
 
-		apiVersion, err := extractAPIVersion(t.Content)
-		if err != nil {
-			return nil, err
-		}
-
-		if apiVersion == "" {
+		resources := extractResources(t.Content)
+		if len(resources) == 0 {
 			continue
 		}
 
-		r.Compatible = isCompatible(kubeAPIVersions.APIVersions, apiVersion)
+		for _, resource := range resources {
+			if !isServed(kubeAPIVersions, resource.APIVersion, resource.Kind) {
+				r.Compatible = false
+			}
+		}
 		results = append(results, r)
 	}
 
 	return results, nil
 }
 
-func isCompatible(versions []string, version string) bool {
-	exists := false
-	for _, v := range versions {
-		if v == version {
-			exists = true
-			break
+// AnalyzeSubcharts analyzes the templates of every subchart in the tree, each result names its subchart by values scope.
+func (a analytic) AnalyzeSubcharts(subcharts []model.Subchart, kubeAPIVersions model.KubernetesAPIVersion) ([]model.AnalyticsResult, error) {
+	var results []model.AnalyticsResult
+
+	for _, subchart := range subcharts {
+		subchartResults, err := a.Analyze(subchart.Templates, kubeAPIVersions)
+		if err != nil {
+			return nil, fmt.Errorf("subchart %s: %w", subchart.ValuesScope, err)
+		}
+
+		for _, r := range subchartResults {
+			r.Subchart = subchart.ValuesScope
+			results = append(results, r)
+		}
+
+		nestedResults, err := a.AnalyzeSubcharts(subchart.Dependencies, kubeAPIVersions)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, nestedResults...)
 	}
 
-	if !exists {
+	return results, nil
+}
+
+// isServed tells whether the kube version serves the kind in apiVersion. Kube versions without kinds data for the
+// apiVersion serve every kind of it, as does an unknown kind.
+func isServed(kubeAPIVersions model.KubernetesAPIVersion, apiVersion, kind string) bool {
+	if !contains(kubeAPIVersions.APIVersions, apiVersion) {
 		return false
 	}
 
-	return true
+	kinds, ok := kubeAPIVersions.Kinds[apiVersion]
+	if !ok || kind == "" {
+		return true
+	}
+
+	return contains(kinds, kind)
 }
 
-func extractAPIVersion(template string) (string, error) {
-	var resource model.KubeResourceCommonSpec
-	lines := strings.Split(template, "\n")
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
 
-	for _, line := range lines {
-		if strings.Contains(line, "apiVersion") {
-			err := yaml.Unmarshal([]byte(line), &resource)
-			if err != nil {
-				fmt.Printf("Error unmarshalling template: %s \n\n\n", err)
-				return "", err
-			}
+	return false
+}
+
+// extractResources returns the apiVersion and kind of every document of a template. Only keys at the start of a
+// line are read, nested ones like the apiVersion of a fieldRef are not the apiVersion of the resource.
+func extractResources(template string) []model.KubeResourceCommonSpec {
+	var resources []model.KubeResourceCommonSpec
+
+	for _, document := range documentSeparatorRegex.Split(template, -1) {
+		apiVersion := topLevelValue(apiVersionRegex, document)
+		if apiVersion == "" {
+			continue
 		}
+
+		resources = append(resources, model.KubeResourceCommonSpec{
+			APIVersion: apiVersion,
+			Kind:       topLevelValue(kindRegex, document),
+		})
+	}
+
+	return resources
+}
+
+// topLevelValue returns the value of the first key matched by keyRegex, templated values are unknown and empty.
+func topLevelValue(keyRegex *regexp.Regexp, document string) string {
+	submatch := keyRegex.FindStringSubmatch(document)
+	if submatch == nil {
+		return ""
+	}
+
+	value := strings.Trim(strings.TrimSpace(submatch[1]), `"'`)
+	if strings.Contains(value, "{{") {
+		return ""
 	}
 
-	return resource.APIVersion, nil
+	return value
 }
@@ -0,0 +1,223 @@
+package analyzer_test
+
+import (
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var kube116 = model.KubernetesAPIVersion{
+	KubeVersion: "1.16",
+	APIVersions: []string{"apps/v1", "v1", "networking.k8s.io/v1beta1"},
+}
+
+func TestAnalytic_Analyze(t *testing.T) {
+	templates := []model.Template{
+		{
+			Name: "templates/deployment.yaml",
+			Content: `{{- if .Values.enabled }}
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - env:
+        - valueFrom:
+            fieldRef:
+              apiVersion: extensions/v1beta1
+{{- end }}`,
+		},
+		{
+			Name:    "templates/ingress.yaml",
+			Content: "apiVersion: networking.k8s.io/v1beta1\nkind: Ingress\n---\napiVersion: \"extensions/v1beta1\"\nkind: Ingress\n",
+		},
+		{
+			Name:    "templates/templated.yaml",
+			Content: "apiVersion: {{ template \"ingress.apiVersion\" . }}\nkind: Ingress\n",
+		},
+		{
+			Name:    "templates/_helpers.tpl",
+			Content: "{{- define \"ingress.apiVersion\" -}}\n{{- if .Capabilities.APIVersions.Has \"networking.k8s.io/v1\" -}}\n{{- end -}}\n{{- end -}}",
+		},
+	}
+
+	results, err := analyzer.New().Analyze(templates, kube116)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AnalyticsResult{
+		{Template: templates[0], Compatible: true},
+		{Template: templates[1], Compatible: false},
+	}, results)
+}
+
+func TestAnalytic_AnalyzeManifests(t *testing.T) {
+	manifests := []model.Manifest{
+		{
+			Name: "templates/ingress.yaml",
+			Content: `---
+# Source: sample/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: legacy
+  namespace: apps
+`,
+		},
+		{
+			Name:    "templates/service.yaml",
+			Content: "# Source: sample/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		},
+		{
+			Name:    "templates/empty.yaml",
+			Content: "---\n# Source: sample/templates/empty.yaml\n",
+		},
+	}
+
+	results, err := analyzer.New().AnalyzeManifests(manifests, kube116)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ResourceAnalysis{
+		{
+			Manifest:   "templates/ingress.yaml",
+			Resource:   model.ResourceID{APIVersion: "networking.k8s.io/v1beta1", Kind: "Ingress", Name: "web"},
+			Compatible: true,
+		},
+		{
+			Manifest:   "templates/ingress.yaml",
+			Resource:   model.ResourceID{APIVersion: "extensions/v1beta1", Kind: "Ingress", Namespace: "apps", Name: "legacy"},
+			Compatible: false,
+		},
+		{
+			Manifest:   "templates/service.yaml",
+			Resource:   model.ResourceID{APIVersion: "v1", Kind: "Service", Name: "web"},
+			Compatible: true,
+		},
+	}, results)
+}
+
+func TestAnalytic_FindDeprecations(t *testing.T) {
+	deprecations := []model.APIDeprecation{
+		{Group: "extensions", Version: "v1beta1", Kind: "Ingress", DeprecatedIn: "1.14", RemovedIn: "1.22", Replacement: "networking.k8s.io/v1"},
+		{Group: "batch", Version: "v1beta1", Kind: "CronJob", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "batch/v1"},
+		{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy", DeprecatedIn: "1.21", RemovedIn: "1.25"},
+		{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler", DeprecatedIn: "1.23", RemovedIn: "1.26", Replacement: "autoscaling/v2"},
+	}
+	manifests := []model.Manifest{
+		{
+			Name: "templates/resources.yaml",
+			Content: `apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: restricted
+---
+apiVersion: autoscaling/v2beta2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+---
+apiVersion: extensions/v1beta1
+kind: NetworkPolicy
+metadata:
+  name: web
+`,
+		},
+	}
+
+	findings, err := analyzer.New().FindDeprecations(manifests, deprecations, "v1.22.3")
+	assert.NoError(t, err)
+
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Message)
+	}
+	assert.Equal(t, []string{
+		"extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1",
+		"batch/v1beta1 CronJob deprecated in 1.21, removed in 1.25, use batch/v1",
+		"policy/v1beta1 PodSecurityPolicy deprecated in 1.21, removed in 1.25, no replacement",
+	}, messages)
+	assert.Equal(t, model.DeprecationFinding{
+		Manifest:     "templates/resources.yaml",
+		Resource:     model.ResourceID{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "web"},
+		Status:       model.APIRemoved,
+		DeprecatedIn: "1.14",
+		RemovedIn:    "1.22",
+		Replacement:  "networking.k8s.io/v1",
+		Message:      "extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1",
+	}, findings[0])
+	assert.Equal(t, model.APIDeprecated, findings[1].Status)
+}
+
+func TestAnalytic_FindDeprecationsInvalidKubeVersion(t *testing.T) {
+	_, err := analyzer.New().FindDeprecations(nil, nil, "latest")
+	assert.Error(t, err)
+}
+
+func TestAnalytic_AnalyzeManifestsByKind(t *testing.T) {
+	kube121 := model.KubernetesAPIVersion{
+		KubeVersion: "1.21",
+		APIVersions: []string{"policy/v1", "policy/v1beta1", "v1"},
+		Kinds: map[string][]string{
+			"policy/v1":      {"PodDisruptionBudget"},
+			"policy/v1beta1": {"PodDisruptionBudget", "PodSecurityPolicy"},
+		},
+	}
+	manifests := []model.Manifest{
+		{
+			Name:    "templates/pdb.yaml",
+			Content: "apiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: web\n",
+		},
+		{
+			Name:    "templates/psp.yaml",
+			Content: "apiVersion: policy/v1\nkind: PodSecurityPolicy\nmetadata:\n  name: restricted\n",
+		},
+		{
+			Name:    "templates/service.yaml",
+			Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+		},
+	}
+
+	results, err := analyzer.New().AnalyzeManifests(manifests, kube121)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ResourceAnalysis{
+		{
+			Manifest:    "templates/pdb.yaml",
+			Resource:    model.ResourceID{APIVersion: "policy/v1", Kind: "PodDisruptionBudget", Name: "web"},
+			Compatible:  true,
+			ServedKinds: []string{"PodDisruptionBudget"},
+		},
+		{
+			Manifest:    "templates/psp.yaml",
+			Resource:    model.ResourceID{APIVersion: "policy/v1", Kind: "PodSecurityPolicy", Name: "restricted"},
+			Compatible:  false,
+			ServedKinds: []string{"PodDisruptionBudget"},
+		},
+		{
+			Manifest:   "templates/service.yaml",
+			Resource:   model.ResourceID{APIVersion: "v1", Kind: "Service", Name: "web"},
+			Compatible: true,
+		},
+	}, results)
+
+	templateResults, err := analyzer.New().Analyze([]model.Template{
+		{Name: "templates/psp.yaml", Content: manifests[1].Content},
+		{Name: "templates/pdb.yaml", Content: "{{- if .Values.pdb }}\n" + manifests[0].Content + "{{- end }}\n"},
+	}, kube121)
+	assert.NoError(t, err)
+	assert.False(t, templateResults[0].Compatible)
+	assert.True(t, templateResults[1].Compatible)
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"strings"
+)
+
+// FindDeprecations reports the rendered resources whose group/version/kind is deprecated or removed in kubeVersion.
+func (a analytic) FindDeprecations(manifests []model.Manifest, deprecations []model.APIDeprecation, kubeVersion string) ([]model.DeprecationFinding, error) {
+	findings := make([]model.DeprecationFinding, 0)
+
+	target, err := kubeversion.Parse(kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	byGVK := make(map[string]model.APIDeprecation, len(deprecations))
+	for _, deprecation := range deprecations {
+		byGVK[gvkKey(deprecation.Group, deprecation.Version, deprecation.Kind)] = deprecation
+	}
+
+	for _, manifest := range manifests {
+		resources, err := parseResourceHeaders(manifest.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, resource := range resources {
+			group, version := splitAPIVersion(resource.APIVersion)
+			deprecation, ok := byGVK[gvkKey(group, version, resource.Kind)]
+			if !ok {
+				continue
+			}
+
+			status := deprecationStatus(deprecation, target)
+			if status == "" {
+				continue
+			}
+
+			findings = append(findings, model.DeprecationFinding{
+				Manifest:     manifest.Name,
+				Resource:     resource,
+				Status:       status,
+				DeprecatedIn: deprecation.DeprecatedIn,
+				RemovedIn:    deprecation.RemovedIn,
+				Replacement:  deprecation.Replacement,
+				Message:      deprecationMessage(resource.APIVersion, resource.Kind, status, deprecation),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+func deprecationStatus(deprecation model.APIDeprecation, target kubeversion.Version) string {
+	if removedIn, err := kubeversion.Parse(deprecation.RemovedIn); err == nil && !target.Before(removedIn) {
+		return model.APIRemoved
+	}
+
+	if deprecatedIn, err := kubeversion.Parse(deprecation.DeprecatedIn); err == nil && !target.Before(deprecatedIn) {
+		return model.APIDeprecated
+	}
+
+	return ""
+}
+
+// deprecationMessage reads like "extensions/v1beta1 Ingress removed in 1.22, use networking.k8s.io/v1".
+func deprecationMessage(apiVersion, kind, status string, deprecation model.APIDeprecation) string {
+	parts := []string{fmt.Sprintf("%s %s", apiVersion, kind)}
+
+	if status == model.APIRemoved {
+		parts[0] += " removed in " + deprecation.RemovedIn
+	} else {
+		parts[0] += " deprecated in " + deprecation.DeprecatedIn
+		if deprecation.RemovedIn != "" {
+			parts = append(parts, "removed in "+deprecation.RemovedIn)
+		}
+	}
+
+	if deprecation.Replacement != "" {
+		parts = append(parts, "use "+deprecation.Replacement)
+	} else {
+		parts = append(parts, "no replacement")
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func gvkKey(group, version, kind string) string {
+	return group + "/" + version + "/" + kind
+}
+
+// splitAPIVersion splits an apiVersion into its group and version, the core group is empty.
+func splitAPIVersion(apiVersion string) (string, string) {
+	i := strings.LastIndex(apiVersion, "/")
+	if i < 0 {
+		return "", apiVersion
+	}
+
+	return apiVersion[:i], apiVersion[i+1:]
+}
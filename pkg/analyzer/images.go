@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultRegistry = "docker.io"
+	defaultTag      = "latest"
+)
+
+// ListImages lists the image of every container, init container and ephemeral container of the rendered workloads,
+// in the order of the manifests. Containers without an image are skipped.
+func (a analytic) ListImages(manifests []model.Manifest) ([]model.ContainerImage, error) {
+	images := make([]model.ContainerImage, 0)
+
+	for _, manifest := range manifests {
+		objects, err := kubeobject.Parse(manifest.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, object := range objects {
+			spec, specPath, ok := podSpec(object)
+			if !ok {
+				continue
+			}
+
+			for _, c := range podContainers(spec, specPath, containersField, initContainersField, ephemeralContainersField) {
+				image := kubeobject.NestedString(c.Fields, "image")
+				if image == "" {
+					continue
+				}
+
+				reference := parseImage(image)
+				if reference.Tag == "" && reference.Digest == "" {
+					reference.Tag = defaultTag
+				}
+
+				images = append(images, model.ContainerImage{
+					ImageReference: reference,
+					Manifest:       manifest.Name,
+					Resource:       kubeobject.ID(object),
+					Container:      c.Name,
+					ContainerType:  c.Type,
+				})
+			}
+		}
+	}
+
+	return images, nil
+}
+
+// parseImage splits an image into registry, repository, tag and digest, the tag is empty when the image has none.
+// The first component is the registry when it looks like a host, like registry.local:5000 or localhost, images of
+// docker.io without an organization are in its library.
+func parseImage(image string) model.ImageReference {
+	reference := model.ImageReference{Image: image, Registry: defaultRegistry}
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference.Digest = name[:i], name[i+1:]
+	}
+
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference.Tag = name[:i], name[i+1:]
+	}
+
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			reference.Registry, name = host, name[i+1:]
+		}
+	}
+
+	if reference.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	reference.Repository = name
+
+	return reference
+}
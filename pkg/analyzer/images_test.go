@@ -0,0 +1,83 @@
+package analyzer_test
+
+import (
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const debuggedPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: apps
+spec:
+  containers:
+    - name: web
+      image: nginx
+    - name: sidecar
+      image: quay.io/prometheus/node-exporter:v1.7.0
+  ephemeralContainers:
+    - name: debugger
+      image: localhost/debug/tools:1.0@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+data:
+  image: nginx:1.25
+`
+
+func TestAnalytic_ListImages(t *testing.T) {
+	manifests := []model.Manifest{
+		{Name: "templates/deployment.yaml", Content: insecureDeployment},
+		{Name: "templates/pod.yaml", Content: debuggedPod},
+		{Name: "templates/cronjob.yaml", Content: hardenedCronJob},
+	}
+
+	images, err := analyzer.New().ListImages(manifests)
+	assert.NoError(t, err)
+
+	deployment := model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "apps", Name: "web"}
+	pod := model.ResourceID{APIVersion: "v1", Kind: "Pod", Namespace: "apps", Name: "web"}
+	assert.Equal(t, []model.ContainerImage{
+		{
+			ImageReference: model.ImageReference{Image: "registry.local:5000/web", Registry: "registry.local:5000", Repository: "web", Tag: "latest"},
+			Manifest:       "templates/deployment.yaml", Resource: deployment, Container: "web", ContainerType: "containers",
+		},
+		{
+			ImageReference: model.ImageReference{Image: "busybox:1.36", Registry: "docker.io", Repository: "library/busybox", Tag: "1.36"},
+			Manifest:       "templates/deployment.yaml", Resource: deployment, Container: "setup", ContainerType: "initContainers",
+		},
+		{
+			ImageReference: model.ImageReference{Image: "nginx", Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+			Manifest:       "templates/pod.yaml", Resource: pod, Container: "web", ContainerType: "containers",
+		},
+		{
+			ImageReference: model.ImageReference{Image: "quay.io/prometheus/node-exporter:v1.7.0", Registry: "quay.io", Repository: "prometheus/node-exporter", Tag: "v1.7.0"},
+			Manifest:       "templates/pod.yaml", Resource: pod, Container: "sidecar", ContainerType: "containers",
+		},
+		{
+			ImageReference: model.ImageReference{
+				Image:      "localhost/debug/tools:1.0@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+				Registry:   "localhost",
+				Repository: "debug/tools",
+				Tag:        "1.0",
+				Digest:     "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+			},
+			Manifest: "templates/pod.yaml", Resource: pod, Container: "debugger", ContainerType: "ephemeralContainers",
+		},
+		{
+			ImageReference: model.ImageReference{
+				Image:      "backup@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+				Registry:   "docker.io",
+				Repository: "library/backup",
+				Digest:     "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945",
+			},
+			Manifest: "templates/cronjob.yaml", Resource: model.ResourceID{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"}, Container: "backup", ContainerType: "containers",
+		},
+	}, images)
+}
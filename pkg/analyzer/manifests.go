@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"fmt"
+)
+
+// AnalyzeManifests checks every resource of the rendered manifests, so templated apiVersions and files holding
+// several documents are analyzed resource by resource. Each result lists the kinds its apiVersion serves.
+func (a analytic) AnalyzeManifests(manifests []model.Manifest, kubeAPIVersions model.KubernetesAPIVersion) ([]model.ResourceAnalysis, error) {
+	results := make([]model.ResourceAnalysis, 0)
+
+	for _, manifest := range manifests {
+		resources, err := parseResourceHeaders(manifest.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, resource := range resources {
+			results = append(results, model.ResourceAnalysis{
+				Manifest:    manifest.Name,
+				Resource:    resource,
+				Compatible:  isServed(kubeAPIVersions, resource.APIVersion, resource.Kind),
+				ServedKinds: kubeAPIVersions.Kinds[resource.APIVersion],
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// parseResourceHeaders returns the identity of every resource of a manifest.
+func parseResourceHeaders(content string) ([]model.ResourceID, error) {
+	objects, err := kubeobject.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]model.ResourceID, 0, len(objects))
+	for _, object := range objects {
+		resources = append(resources, kubeobject.ID(object))
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"strings"
+)
+
+const builtinRuleSource = "builtin"
+
+// Rule is a policy check of a rendered resource, Check returns a violation for every offending field of the object.
+type Rule struct {
+	model.PolicyRule
+	Check func(object map[string]interface{}) []Violation
+}
+
+// Violation is a field violating a rule, Path is its JSON path.
+type Violation struct {
+	Path    string
+	Message string
+}
+
+// BuiltinRules are the security and best practice checks every chart is held to.
+func BuiltinRules() []Rule {
+	return []Rule{
+		builtinRule("privileged-container", model.SeverityHigh, "Containers must not run privileged", checkPrivileged),
+		builtinRule("run-as-root", model.SeverityHigh, "Containers must not run as root", checkRunAsRoot),
+		builtinRule("host-path-volume", model.SeverityHigh, "Pods must not mount paths of the host", checkHostPath),
+		builtinRule("missing-resources", model.SeverityMedium, "Containers must set resource requests and limits", checkResources),
+		builtinRule("latest-image-tag", model.SeverityMedium, "Images must be pinned to a tag other than latest or a digest", checkImageTag),
+		builtinRule("node-port-service", model.SeverityMedium, "Services should not expose node ports", checkNodePort),
+		builtinRule("missing-probes", model.SeverityLow, "Long running containers should have liveness and readiness probes", checkProbes),
+	}
+}
+
+func builtinRule(name, severity, description string, check func(object map[string]interface{}) []Violation) Rule {
+	return Rule{
+		PolicyRule: model.PolicyRule{Name: name, Severity: severity, Description: description, Source: builtinRuleSource},
+		Check:      check,
+	}
+}
+
+// CheckPolicies runs the built-in and user-defined rules over every resource of the rendered manifests, findings are
+// in the order of the manifests, then of the rules.
+func (a analytic) CheckPolicies(manifests []model.Manifest) ([]model.PolicyFinding, error) {
+	findings := make([]model.PolicyFinding, 0)
+	rules := a.rules.Rules()
+
+	for _, manifest := range manifests {
+		objects, err := kubeobject.Parse(manifest.Content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, object := range objects {
+			resource := kubeobject.ID(object)
+			for _, rule := range rules {
+				for _, violation := range rule.Check(object) {
+					findings = append(findings, model.PolicyFinding{
+						Rule:     rule.Name,
+						Severity: rule.Severity,
+						Manifest: manifest.Name,
+						Resource: resource,
+						Path:     violation.Path,
+						Message:  violation.Message,
+					})
+				}
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+func checkPrivileged(object map[string]interface{}) []Violation {
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, c := range podContainers(spec, specPath, containersField, initContainersField, ephemeralContainersField) {
+		if privileged, _ := kubeobject.NestedValue(c.Fields, "securityContext", "privileged"); privileged == true {
+			violations = append(violations, Violation{
+				Path:    c.Path + ".securityContext.privileged",
+				Message: fmt.Sprintf("container %s runs privileged", c.Name),
+			})
+		}
+	}
+
+	return violations
+}
+
+// checkRunAsRoot flags containers running as user 0 and containers that run as root when the image does, the
+// security context of a container overrides the one of its pod.
+func checkRunAsRoot(object map[string]interface{}) []Violation {
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, c := range podContainers(spec, specPath, containersField, initContainersField) {
+		userPath := c.Path + ".securityContext.runAsUser"
+		user, userSet := kubeobject.NestedValue(c.Fields, "securityContext", "runAsUser")
+		if !userSet {
+			userPath = specPath + ".securityContext.runAsUser"
+			user, userSet = kubeobject.NestedValue(spec, "securityContext", "runAsUser")
+		}
+
+		nonRoot, nonRootSet := kubeobject.NestedValue(c.Fields, "securityContext", "runAsNonRoot")
+		if !nonRootSet {
+			nonRoot, _ = kubeobject.NestedValue(spec, "securityContext", "runAsNonRoot")
+		}
+
+		switch {
+		case userSet && fmt.Sprint(user) == "0":
+			violations = append(violations, Violation{
+				Path:    userPath,
+				Message: fmt.Sprintf("container %s runs as root", c.Name),
+			})
+		case !userSet && nonRoot != true:
+			violations = append(violations, Violation{
+				Path:    c.Path + ".securityContext.runAsNonRoot",
+				Message: fmt.Sprintf("container %s may run as root, neither runAsNonRoot nor runAsUser is set", c.Name),
+			})
+		}
+	}
+
+	return violations
+}
+
+func checkHostPath(object map[string]interface{}) []Violation {
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	volumes, _ := spec["volumes"].([]interface{})
+	for i, item := range volumes {
+		volume, _ := item.(map[string]interface{})
+		if _, ok := kubeobject.NestedMap(volume, "hostPath"); !ok {
+			continue
+		}
+
+		violations = append(violations, Violation{
+			Path:    fmt.Sprintf("%s.volumes[%d].hostPath", specPath, i),
+			Message: fmt.Sprintf("volume %s mounts %s of the host", kubeobject.NestedString(volume, "name"), kubeobject.NestedString(volume, "hostPath", "path")),
+		})
+	}
+
+	return violations
+}
+
+func checkResources(object map[string]interface{}) []Violation {
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, c := range podContainers(spec, specPath, containersField, initContainersField) {
+		for _, field := range []string{"requests", "limits"} {
+			if resources, _ := kubeobject.NestedMap(c.Fields, "resources", field); len(resources) != 0 {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Path:    c.Path + ".resources." + field,
+				Message: fmt.Sprintf("container %s has no resource %s", c.Name, field),
+			})
+		}
+	}
+
+	return violations
+}
+
+func checkImageTag(object map[string]interface{}) []Violation {
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, c := range podContainers(spec, specPath, containersField, initContainersField, ephemeralContainersField) {
+		image := kubeobject.NestedString(c.Fields, "image")
+		if image == "" || strings.Contains(image, "@") {
+			continue
+		}
+
+		var message string
+		switch tag := parseImage(image).Tag; tag {
+		case "":
+			message = fmt.Sprintf("container %s image %s has no tag, latest is pulled", c.Name, image)
+		case "latest":
+			message = fmt.Sprintf("container %s uses the latest tag of %s", c.Name, image)
+		default:
+			continue
+		}
+
+		violations = append(violations, Violation{Path: c.Path + ".image", Message: message})
+	}
+
+	return violations
+}
+
+func checkNodePort(object map[string]interface{}) []Violation {
+	if kubeobject.NestedString(object, "kind") != "Service" || kubeobject.NestedString(object, "spec", "type") != "NodePort" {
+		return nil
+	}
+
+	return []Violation{{Path: "$.spec.type", Message: "service exposes its ports on every node"}}
+}
+
+// checkProbes skips jobs, their containers are expected to terminate.
+func checkProbes(object map[string]interface{}) []Violation {
+	if kind := kubeobject.NestedString(object, "kind"); kind == "Job" || kind == "CronJob" {
+		return nil
+	}
+
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return nil
+	}
+
+	var violations []Violation
+	for _, c := range podContainers(spec, specPath, containersField) {
+		for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+			if _, ok := c.Fields[probe]; ok {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Path:    c.Path + "." + probe,
+				Message: fmt.Sprintf("container %s has no %s", c.Name, probe),
+			})
+		}
+	}
+
+	return violations
+}
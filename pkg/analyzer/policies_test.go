@@ -0,0 +1,91 @@
+package analyzer_test
+
+import (
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const insecureDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: apps
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: setup
+          image: busybox:1.36
+          securityContext:
+            privileged: true
+            runAsUser: 0
+          resources:
+            requests:
+              cpu: 10m
+            limits:
+              cpu: 10m
+      containers:
+        - name: web
+          image: registry.local:5000/web
+          volumeMounts:
+            - name: docker
+              mountPath: /var/run/docker.sock
+      volumes:
+        - name: docker
+          hostPath:
+            path: /var/run/docker.sock
+`
+
+const hardenedCronJob = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          securityContext:
+            runAsNonRoot: true
+          containers:
+            - name: backup
+              image: backup@sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945
+              resources:
+                requests:
+                  memory: 64Mi
+                limits:
+                  memory: 64Mi
+`
+
+func TestAnalytic_CheckPolicies(t *testing.T) {
+	manifests := []model.Manifest{
+		{Name: "templates/deployment.yaml", Content: insecureDeployment},
+		{Name: "templates/cronjob.yaml", Content: hardenedCronJob},
+		{Name: "templates/service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  type: NodePort\n"},
+	}
+
+	findings, err := analyzer.New().CheckPolicies(manifests)
+	assert.NoError(t, err)
+
+	deployment := model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "apps", Name: "web"}
+	assert.Equal(t, []model.PolicyFinding{
+		{Rule: "privileged-container", Severity: model.SeverityHigh, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.initContainers[0].securityContext.privileged", Message: "container setup runs privileged"},
+		{Rule: "run-as-root", Severity: model.SeverityHigh, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].securityContext.runAsNonRoot", Message: "container web may run as root, neither runAsNonRoot nor runAsUser is set"},
+		{Rule: "run-as-root", Severity: model.SeverityHigh, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.initContainers[0].securityContext.runAsUser", Message: "container setup runs as root"},
+		{Rule: "host-path-volume", Severity: model.SeverityHigh, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.volumes[0].hostPath", Message: "volume docker mounts /var/run/docker.sock of the host"},
+		{Rule: "missing-resources", Severity: model.SeverityMedium, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].resources.requests", Message: "container web has no resource requests"},
+		{Rule: "missing-resources", Severity: model.SeverityMedium, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].resources.limits", Message: "container web has no resource limits"},
+		{Rule: "latest-image-tag", Severity: model.SeverityMedium, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].image", Message: "container web image registry.local:5000/web has no tag, latest is pulled"},
+		{Rule: "missing-probes", Severity: model.SeverityLow, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].livenessProbe", Message: "container web has no livenessProbe"},
+		{Rule: "missing-probes", Severity: model.SeverityLow, Manifest: "templates/deployment.yaml", Resource: deployment, Path: "$.spec.template.spec.containers[0].readinessProbe", Message: "container web has no readinessProbe"},
+		{Rule: "node-port-service", Severity: model.SeverityMedium, Manifest: "templates/service.yaml", Resource: model.ResourceID{APIVersion: "v1", Kind: "Service", Name: "web"}, Path: "$.spec.type", Message: "service exposes its ports on every node"},
+	}, findings)
+}
+
+func TestAnalytic_CheckPoliciesInvalidManifest(t *testing.T) {
+	_, err := analyzer.New().CheckPolicies([]model.Manifest{{Name: "templates/broken.yaml", Content: "apiVersion: v1\nkind: [\n"}})
+	assert.EqualError(t, err, "parsing templates/broken.yaml: yaml: line 2: did not find expected node content")
+}
@@ -0,0 +1,349 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const rbacGroup = "rbac.authorization.k8s.io"
+
+// escalationVerbs let a subject grant itself permissions it does not have.
+var escalationVerbs = []string{"bind", "escalate", "impersonate"}
+
+// clusterAdminRules are the rules of the built-in cluster-admin ClusterRole, which charts bind without defining it.
+var clusterAdminRules = []rbacRule{
+	{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}},
+}
+
+type rbacRule struct {
+	APIGroups       []string `yaml:"apiGroups"`
+	Resources       []string `yaml:"resources"`
+	ResourceNames   []string `yaml:"resourceNames"`
+	NonResourceURLs []string `yaml:"nonResourceURLs"`
+	Verbs           []string `yaml:"verbs"`
+}
+
+type rbacSubject struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+type rbacObject struct {
+	model.KubeResourceCommonSpec `yaml:",inline"`
+	Metadata                     struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Rules   []rbacRule `yaml:"rules"`
+	RoleRef struct {
+		Kind string `yaml:"kind"`
+		Name string `yaml:"name"`
+	} `yaml:"roleRef"`
+	Subjects []rbacSubject `yaml:"subjects"`
+}
+
+// rbacRole is a Role or ClusterRole of the chart, scope is the namespace of a Role and the cluster for a ClusterRole.
+type rbacRole struct {
+	name  string
+	scope string
+	rules []rbacRule
+}
+
+// AnalyzeRBAC builds the permission matrix of the rendered roles and bindings. The rules of a role are granted in the
+// scope of each binding to it: the cluster for a ClusterRoleBinding and the namespace of a RoleBinding. Roles without
+// a binding are listed in their own scope without subjects. namespace is the release namespace, the one of the
+// namespaced resources rendered without a namespace.
+func (a analytic) AnalyzeRBAC(manifests []model.Manifest, namespace string) (model.RBACReport, error) {
+	var (
+		roles           []*rbacRole
+		bindings        []rbacObject
+		serviceAccounts = make([]string, 0)
+	)
+
+	rolesByName := map[string]*rbacRole{}
+	for _, manifest := range manifests {
+		objects, err := parseRBACObjects(manifest.Content)
+		if err != nil {
+			return model.RBACReport{}, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, object := range objects {
+			objectNamespace := object.Metadata.Namespace
+			if objectNamespace == "" {
+				objectNamespace = namespace
+			}
+
+			switch object.Kind {
+			case "ServiceAccount":
+				serviceAccounts = append(serviceAccounts, objectNamespace+"/"+object.Metadata.Name)
+			case "Role", "ClusterRole":
+				role := &rbacRole{name: roleName(object.Kind, objectNamespace, object.Metadata.Name), scope: model.RBACClusterScope, rules: object.Rules}
+				if object.Kind == "Role" {
+					role.scope = objectNamespace
+				}
+				roles = append(roles, role)
+				rolesByName[role.name] = role
+			case "RoleBinding", "ClusterRoleBinding":
+				object.Metadata.Namespace = objectNamespace
+				bindings = append(bindings, object)
+			}
+		}
+	}
+
+	matrix := permissionMatrix{}
+	findings := make([]model.RBACFinding, 0)
+	bound := map[string]bool{}
+	for _, binding := range bindings {
+		scope := model.RBACClusterScope
+		if binding.Kind == "RoleBinding" {
+			scope = binding.Metadata.Namespace
+		}
+
+		name := roleName(binding.RoleRef.Kind, binding.Metadata.Namespace, binding.RoleRef.Name)
+		var rules []rbacRule
+		switch role, ok := rolesByName[name]; {
+		case ok:
+			rules = role.rules
+		case name == roleName("ClusterRole", "", "cluster-admin"):
+			rules = clusterAdminRules
+		default:
+			findings = append(findings, model.RBACFinding{
+				Severity: model.SeverityLow,
+				Role:     name,
+				Scope:    scope,
+				Message:  fmt.Sprintf("%s %s binds %s which the chart does not define, its permissions are unknown", binding.Kind, binding.Metadata.Name, name),
+			})
+			continue
+		}
+
+		bound[name] = true
+		matrix.add(scope, name, rules, bindingSubjects(binding))
+	}
+
+	for _, role := range roles {
+		if !bound[role.name] {
+			matrix.add(role.scope, role.name, role.rules, nil)
+		}
+	}
+
+	permissions := matrix.permissions()
+	sort.Strings(serviceAccounts)
+
+	return model.RBACReport{
+		Permissions:     permissions,
+		ServiceAccounts: serviceAccounts,
+		Findings:        append(findings, rbacFindings(permissions)...),
+	}, nil
+}
+
+// parseRBACObjects returns the roles, bindings and service accounts of a manifest, other resources are skipped.
+func parseRBACObjects(content string) ([]rbacObject, error) {
+	objects, err := kubeobject.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var rbacObjects []rbacObject
+	for _, object := range objects {
+		id := kubeobject.ID(object)
+		group, _ := splitAPIVersion(id.APIVersion)
+		isRBAC := (group == rbacGroup && id.Kind != "") || (id.APIVersion == "v1" && id.Kind == "ServiceAccount")
+		if !isRBAC {
+			continue
+		}
+
+		// the rules, role reference and subjects are typed by re-encoding the decoded document
+		document, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+
+		var rbac rbacObject
+		err = yaml.Unmarshal(document, &rbac)
+		if err != nil {
+			return nil, err
+		}
+		rbacObjects = append(rbacObjects, rbac)
+	}
+
+	return rbacObjects, nil
+}
+
+func roleName(kind, namespace, name string) string {
+	if kind == "Role" {
+		return fmt.Sprintf("Role %s/%s", namespace, name)
+	}
+	return "ClusterRole " + name
+}
+
+// bindingSubjects formats the subjects of a binding, service accounts default to the namespace of the binding.
+func bindingSubjects(binding rbacObject) []string {
+	var subjects []string
+	for _, subject := range binding.Subjects {
+		if subject.Kind != "ServiceAccount" {
+			subjects = append(subjects, subject.Kind+" "+subject.Name)
+			continue
+		}
+
+		namespace := subject.Namespace
+		if namespace == "" {
+			namespace = binding.Metadata.Namespace
+		}
+		subjects = append(subjects, fmt.Sprintf("ServiceAccount %s/%s", namespace, subject.Name))
+	}
+	return subjects
+}
+
+// permissionMatrix merges the rows granted by several rules or bindings of a role on the same resource and scope.
+type permissionMatrix map[string]*model.RBACPermission
+
+func (m permissionMatrix) add(scope, role string, rules []rbacRule, subjects []string) {
+	for _, rule := range rules {
+		for _, target := range ruleTargets(rule) {
+			key := strings.Join([]string{scope, role, target[0], target[1], strings.Join(rule.ResourceNames, ",")}, "|")
+			permission, ok := m[key]
+			if !ok {
+				permission = &model.RBACPermission{
+					Scope:         scope,
+					APIGroup:      target[0],
+					Resource:      target[1],
+					ResourceNames: rule.ResourceNames,
+					Role:          role,
+				}
+				m[key] = permission
+			}
+
+			permission.Verbs = mergeSorted(permission.Verbs, rule.Verbs)
+			permission.Subjects = mergeSorted(permission.Subjects, subjects)
+			permission.Wildcard = permission.APIGroup == "*" || strings.Contains(permission.Resource, "*") || contains(permission.Verbs, "*")
+		}
+	}
+}
+
+// ruleTargets returns the API group and resource pairs of a rule, non-resource URLs have no API group.
+func ruleTargets(rule rbacRule) [][2]string {
+	var targets [][2]string
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			targets = append(targets, [2]string{group, resource})
+		}
+	}
+	for _, url := range rule.NonResourceURLs {
+		targets = append(targets, [2]string{"", url})
+	}
+	return targets
+}
+
+// permissions returns the rows of the matrix, cluster wide grants first, then by namespace, role, API group and
+// resource.
+func (m permissionMatrix) permissions() []model.RBACPermission {
+	permissions := make([]model.RBACPermission, 0, len(m))
+	for _, permission := range m {
+		permissions = append(permissions, *permission)
+	}
+
+	sort.Slice(permissions, func(i, j int) bool {
+		a, b := permissions[i], permissions[j]
+		switch {
+		case a.Scope != b.Scope:
+			return a.Scope == model.RBACClusterScope || (b.Scope != model.RBACClusterScope && a.Scope < b.Scope)
+		case a.Role != b.Role:
+			return a.Role < b.Role
+		case a.APIGroup != b.APIGroup:
+			return a.APIGroup < b.APIGroup
+		case a.Resource != b.Resource:
+			return a.Resource < b.Resource
+		default:
+			return strings.Join(a.ResourceNames, ",") < strings.Join(b.ResourceNames, ",")
+		}
+	})
+
+	return permissions
+}
+
+// rbacFindings highlights the roles granting everything like cluster-admin, the wildcard grants of other roles and
+// the verbs allowing privilege escalation.
+func rbacFindings(permissions []model.RBACPermission) []model.RBACFinding {
+	adminLike := map[string]bool{}
+	for _, permission := range permissions {
+		if permission.APIGroup == "*" && permission.Resource == "*" && contains(permission.Verbs, "*") && len(permission.ResourceNames) == 0 {
+			adminLike[permission.Role+"|"+permission.Scope] = true
+		}
+	}
+
+	var findings []model.RBACFinding
+	reported := map[string]bool{}
+	for _, permission := range permissions {
+		key := permission.Role + "|" + permission.Scope
+		switch {
+		case adminLike[key] && reported[key]:
+			continue
+		case adminLike[key]:
+			reported[key] = true
+			findings = append(findings, model.RBACFinding{
+				Severity: model.SeverityHigh,
+				Role:     permission.Role,
+				Scope:    permission.Scope,
+				Message:  fmt.Sprintf("%s grants every verb on every resource %s, like cluster-admin", permission.Role, scopeText(permission.Scope)),
+			})
+			continue
+		case permission.Wildcard:
+			findings = append(findings, model.RBACFinding{
+				Severity: model.SeverityMedium,
+				Role:     permission.Role,
+				Scope:    permission.Scope,
+				Message:  fmt.Sprintf("%s grants %s on %s %s", permission.Role, strings.Join(permission.Verbs, ","), resourceText(permission), scopeText(permission.Scope)),
+			})
+		}
+
+		for _, verb := range escalationVerbs {
+			if contains(permission.Verbs, verb) {
+				findings = append(findings, model.RBACFinding{
+					Severity: model.SeverityHigh,
+					Role:     permission.Role,
+					Scope:    permission.Scope,
+					Message:  fmt.Sprintf("%s grants %s on %s %s, which allows privilege escalation", permission.Role, verb, resourceText(permission), scopeText(permission.Scope)),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func scopeText(scope string) string {
+	if scope == model.RBACClusterScope {
+		return "in the cluster"
+	}
+	return "in namespace " + scope
+}
+
+func resourceText(permission model.RBACPermission) string {
+	if permission.APIGroup == "" {
+		return permission.Resource
+	}
+	return permission.APIGroup + "/" + permission.Resource
+}
+
+// mergeSorted returns the sorted union of the values, without duplicates.
+func mergeSorted(values, others []string) []string {
+	if len(others) == 0 {
+		return values
+	}
+
+	var merged []string
+	for _, value := range append(append([]string{}, values...), others...) {
+		if !contains(merged, value) {
+			merged = append(merged, value)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
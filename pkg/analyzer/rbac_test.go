@@ -0,0 +1,160 @@
+package analyzer_test
+
+import (
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const operatorRBAC = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: operator
+rules:
+  - apiGroups: [""]
+    resources: ["pods", "pods/log"]
+    verbs: ["get", "list"]
+  - apiGroups: [""]
+    resources: ["pods"]
+    verbs: ["watch", "get"]
+  - apiGroups: ["apps"]
+    resources: ["*"]
+    verbs: ["*"]
+  - nonResourceURLs: ["/metrics"]
+    verbs: ["get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: operator
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: operator
+subjects:
+  - kind: ServiceAccount
+    name: operator
+    namespace: apps
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: leader-election
+rules:
+  - apiGroups: ["coordination.k8s.io"]
+    resources: ["leases"]
+    resourceNames: ["operator-lock"]
+    verbs: ["get", "update"]
+  - apiGroups: ["rbac.authorization.k8s.io"]
+    resources: ["roles"]
+    verbs: ["bind"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: leader-election
+roleRef:
+  kind: Role
+  name: leader-election
+subjects:
+  - kind: ServiceAccount
+    name: operator
+  - kind: Group
+    name: admins
+`
+
+const adminBindings = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: operator-admin
+roleRef:
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+  - kind: User
+    name: jane
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: viewer
+  namespace: monitoring
+roleRef:
+  kind: ClusterRole
+  name: view
+subjects:
+  - kind: ServiceAccount
+    name: operator
+    namespace: apps
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: unused
+rules:
+  - apiGroups: ["batch"]
+    resources: ["jobs"]
+    verbs: ["create"]
+`
+
+func TestAnalytic_AnalyzeRBAC(t *testing.T) {
+	manifests := []model.Manifest{
+		{Name: "templates/rbac.yaml", Content: operatorRBAC},
+		{Name: "templates/deployment.yaml", Content: insecureDeployment},
+	}
+
+	report, err := analyzer.New().AnalyzeRBAC(manifests, "apps")
+	assert.NoError(t, err)
+
+	operator := []string{"ServiceAccount apps/operator"}
+	assert.Equal(t, []model.RBACPermission{
+		{Scope: "cluster", APIGroup: "", Resource: "/metrics", Verbs: []string{"get"}, Role: "ClusterRole operator", Subjects: operator},
+		{Scope: "cluster", APIGroup: "", Resource: "pods", Verbs: []string{"get", "list", "watch"}, Role: "ClusterRole operator", Subjects: operator},
+		{Scope: "cluster", APIGroup: "", Resource: "pods/log", Verbs: []string{"get", "list"}, Role: "ClusterRole operator", Subjects: operator},
+		{Scope: "cluster", APIGroup: "apps", Resource: "*", Verbs: []string{"*"}, Role: "ClusterRole operator", Subjects: operator, Wildcard: true},
+		{
+			Scope: "apps", APIGroup: "coordination.k8s.io", Resource: "leases", ResourceNames: []string{"operator-lock"},
+			Verbs: []string{"get", "update"}, Role: "Role apps/leader-election", Subjects: []string{"Group admins", "ServiceAccount apps/operator"},
+		},
+		{
+			Scope: "apps", APIGroup: "rbac.authorization.k8s.io", Resource: "roles",
+			Verbs: []string{"bind"}, Role: "Role apps/leader-election", Subjects: []string{"Group admins", "ServiceAccount apps/operator"},
+		},
+	}, report.Permissions)
+	assert.Equal(t, []string{"apps/operator"}, report.ServiceAccounts)
+	assert.Equal(t, []model.RBACFinding{
+		{Severity: model.SeverityMedium, Role: "ClusterRole operator", Scope: "cluster", Message: "ClusterRole operator grants * on apps/* in the cluster"},
+		{Severity: model.SeverityHigh, Role: "Role apps/leader-election", Scope: "apps", Message: "Role apps/leader-election grants bind on rbac.authorization.k8s.io/roles in namespace apps, which allows privilege escalation"},
+	}, report.Findings)
+}
+
+func TestAnalytic_AnalyzeRBACWithRolesOutsideTheChart(t *testing.T) {
+	manifests := []model.Manifest{{Name: "templates/bindings.yaml", Content: adminBindings}}
+
+	report, err := analyzer.New().AnalyzeRBAC(manifests, "default")
+	assert.NoError(t, err)
+
+	jane := []string{"User jane"}
+	assert.Equal(t, []model.RBACPermission{
+		{Scope: "cluster", APIGroup: "", Resource: "*", Verbs: []string{"*"}, Role: "ClusterRole cluster-admin", Subjects: jane, Wildcard: true},
+		{Scope: "cluster", APIGroup: "*", Resource: "*", Verbs: []string{"*"}, Role: "ClusterRole cluster-admin", Subjects: jane, Wildcard: true},
+		{Scope: "cluster", APIGroup: "batch", Resource: "jobs", Verbs: []string{"create"}, Role: "ClusterRole unused"},
+	}, report.Permissions)
+	assert.Empty(t, report.ServiceAccounts)
+	assert.Equal(t, []model.RBACFinding{
+		{Severity: model.SeverityLow, Role: "ClusterRole view", Scope: "monitoring", Message: "RoleBinding viewer binds ClusterRole view which the chart does not define, its permissions are unknown"},
+		{Severity: model.SeverityHigh, Role: "ClusterRole cluster-admin", Scope: "cluster", Message: "ClusterRole cluster-admin grants every verb on every resource in the cluster, like cluster-admin"},
+	}, report.Findings)
+}
+
+func TestAnalytic_AnalyzeRBACWithInvalidManifest(t *testing.T) {
+	_, err := analyzer.New().AnalyzeRBAC([]model.Manifest{{Name: "templates/rbac.yaml", Content: "kind: [Role"}}, "default")
+	assert.Error(t, err)
+}
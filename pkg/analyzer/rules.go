@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"bytes"
+	"chart-viewer/pkg/expression"
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidRule = errors.New("invalid policy rule")
+
+// ruleVariables are the variables of rule expressions: the rendered resource, and the containers, init containers
+// and ephemeral containers of its pod spec, empty for kinds not running pods.
+var ruleVariables = []string{"object", "containers"}
+
+// RuleSet holds the built-in rules and the user-defined rules of a directory, it is reloaded while in use.
+type RuleSet struct {
+	dir   string
+	mutex sync.RWMutex
+	rules []Rule
+}
+
+// NewRuleSet loads the user-defined rules of dir next to the built-in ones, no dir has the built-in rules only.
+func NewRuleSet(dir string) (*RuleSet, error) {
+	ruleSet := &RuleSet{dir: dir, rules: BuiltinRules()}
+	if dir == "" {
+		return ruleSet, nil
+	}
+
+	return ruleSet, ruleSet.Reload()
+}
+
+func (r *RuleSet) Rules() []Rule {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.rules
+}
+
+// Reload reads the rule files of the directory again. The rules in use are kept when one of the files is invalid.
+func (r *RuleSet) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+
+	custom, err := LoadRules(r.dir)
+	if err != nil {
+		return err
+	}
+
+	rules := append(BuiltinRules(), custom...)
+	seen := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		if source, ok := seen[rule.Name]; ok {
+			return fmt.Errorf("%w: %s of %s is already defined in %s", ErrInvalidRule, rule.Name, rule.Source, source)
+		}
+		seen[rule.Name] = rule.Source
+	}
+
+	r.mutex.Lock()
+	r.rules = rules
+	r.mutex.Unlock()
+
+	return nil
+}
+
+func (a analytic) PolicyRules() []model.PolicyRule {
+	rules := a.rules.Rules()
+
+	policyRules := make([]model.PolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		policyRules = append(policyRules, rule.PolicyRule)
+	}
+
+	return policyRules
+}
+
+func (a analytic) ReloadPolicyRules() error {
+	return a.rules.Reload()
+}
+
+// LoadRules reads the rules of the .yaml and .yml files of dir in name order. A file holds a list of rules under
+// the rules key, and may have several documents.
+func LoadRules(dir string) ([]Rule, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var rules []Rule
+	for _, path := range paths {
+		fileRules, err := loadRuleFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	return rules, nil
+}
+
+func loadRuleFile(path string) ([]Rule, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var file struct {
+			Rules []model.PolicyRule `yaml:"rules"`
+		}
+		err = decoder.Decode(&file)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: parsing %s: %s", ErrInvalidRule, path, err)
+		}
+
+		for _, policyRule := range file.Rules {
+			policyRule.Source = filepath.Base(path)
+			rule, err := newExpressionRule(policyRule)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s of %s: %s", ErrInvalidRule, policyRule.Name, path, err)
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// newExpressionRule compiles a user-defined rule, a resource violates it when the expression is false. A failing
+// expression is reported as a violation too, has() guards the fields a resource may not set.
+func newExpressionRule(policyRule model.PolicyRule) (Rule, error) {
+	if policyRule.Name == "" {
+		return Rule{}, errors.New("name is required")
+	}
+
+	if policyRule.Severity != model.SeverityHigh && policyRule.Severity != model.SeverityMedium && policyRule.Severity != model.SeverityLow {
+		return Rule{}, fmt.Errorf("severity %q is not high, medium or low", policyRule.Severity)
+	}
+
+	if strings.TrimSpace(policyRule.Expression) == "" {
+		return Rule{}, errors.New("expression is required")
+	}
+
+	program, err := expression.Compile(policyRule.Expression, ruleVariables...)
+	if err != nil {
+		return Rule{}, fmt.Errorf("expression: %w", err)
+	}
+
+	path := policyRule.Path
+	if path == "" {
+		path = "$"
+	}
+
+	message := policyRule.Message
+	if message == "" {
+		message = fmt.Sprintf("%s is false", policyRule.Expression)
+	}
+
+	check := func(object map[string]interface{}) []Violation {
+		if len(policyRule.Kinds) != 0 && !contains(policyRule.Kinds, kubeobject.NestedString(object, "kind")) {
+			return nil
+		}
+
+		valid, err := program.EvalBool(map[string]interface{}{
+			"object":     object,
+			"containers": containerList(object),
+		})
+		if err != nil {
+			return []Violation{{Path: path, Message: fmt.Sprintf("expression failed: %s", err)}}
+		}
+		if valid {
+			return nil
+		}
+
+		return []Violation{{Path: path, Message: message}}
+	}
+
+	return Rule{PolicyRule: policyRule, Check: check}, nil
+}
+
+func containerList(object map[string]interface{}) []interface{} {
+	containers := make([]interface{}, 0)
+
+	spec, specPath, ok := podSpec(object)
+	if !ok {
+		return containers
+	}
+
+	for _, c := range podContainers(spec, specPath, containersField, initContainersField, ephemeralContainersField) {
+		containers = append(containers, c.Fields)
+	}
+
+	return containers
+}
@@ -0,0 +1,106 @@
+package analyzer_test
+
+import (
+	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/model"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const conventionRules = `rules:
+  - name: team-label
+    severity: medium
+    description: Resources must name their owning team
+    expression: has(object.metadata.labels) && has(object.metadata.labels.team)
+    message: the team label is missing
+    path: $.metadata.labels.team
+  - name: allowed-registries
+    severity: high
+    kinds: [Deployment, CronJob]
+    expression: containers.all(c, c.image.startsWith("registry.local/"))
+    message: images must be pulled from registry.local
+`
+
+func writeRules(t *testing.T, dir, name, content string) {
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestAnalytic_CheckPoliciesWithRules(t *testing.T) {
+	dir := t.TempDir()
+	writeRules(t, dir, "conventions.yaml", conventionRules)
+	writeRules(t, dir, "README.md", "not a rule file")
+
+	ruleSet, err := analyzer.NewRuleSet(dir)
+	assert.NoError(t, err)
+	a := analyzer.NewWithRules(ruleSet)
+
+	manifests := []model.Manifest{
+		{Name: "templates/service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n  labels:\n    team: payments\n"},
+		{Name: "templates/cronjob.yaml", Content: hardenedCronJob},
+	}
+
+	findings, err := a.CheckPolicies(manifests)
+	assert.NoError(t, err)
+
+	cronJob := model.ResourceID{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup"}
+	assert.Equal(t, []model.PolicyFinding{
+		{Rule: "team-label", Severity: model.SeverityMedium, Manifest: "templates/cronjob.yaml", Resource: cronJob, Path: "$.metadata.labels.team", Message: "the team label is missing"},
+		{Rule: "allowed-registries", Severity: model.SeverityHigh, Manifest: "templates/cronjob.yaml", Resource: cronJob, Path: "$", Message: "images must be pulled from registry.local"},
+	}, findings)
+
+	rules := a.PolicyRules()
+	assert.Len(t, rules, len(analyzer.BuiltinRules())+2)
+	assert.Equal(t, model.PolicyRule{
+		Name:       "allowed-registries",
+		Severity:   model.SeverityHigh,
+		Kinds:      []string{"Deployment", "CronJob"},
+		Expression: `containers.all(c, c.image.startsWith("registry.local/"))`,
+		Message:    "images must be pulled from registry.local",
+		Source:     "conventions.yaml",
+	}, rules[len(rules)-1])
+}
+
+func TestRuleSet_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ruleSet, err := analyzer.NewRuleSet(dir)
+	assert.NoError(t, err)
+	assert.Len(t, ruleSet.Rules(), len(analyzer.BuiltinRules()))
+
+	writeRules(t, dir, "conventions.yml", conventionRules)
+	assert.NoError(t, ruleSet.Reload())
+	assert.Len(t, ruleSet.Rules(), len(analyzer.BuiltinRules())+2)
+
+	writeRules(t, dir, "broken.yaml", "rules:\n  - name: broken\n    severity: high\n    expression: object.kind ==\n")
+	err = ruleSet.Reload()
+	assert.True(t, errors.Is(err, analyzer.ErrInvalidRule))
+	assert.Contains(t, err.Error(), "broken of "+filepath.Join(dir, "broken.yaml")+": expression: ERROR: <input>:1:15: Syntax error: mismatched input '<EOF>'")
+	assert.Len(t, ruleSet.Rules(), len(analyzer.BuiltinRules())+2)
+}
+
+func TestLoadRules_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"rules:\n  - severity: high\n    expression: 'true'\n":                                    "name is required",
+		"rules:\n  - name: labels\n    severity: critical\n    expression: 'true'\n":              `severity "critical" is not high, medium or low`,
+		"rules:\n  - name: labels\n    severity: low\n":                                           "expression is required",
+		"rules:\n  - name: labels\n    severity: low\n    expression: resource.kind == 'Pod'\n":   "expression: ERROR: <input>:1:1: undeclared reference to 'resource'",
+		"rules:\n  - name: labels\n    severity: low\n    expression: object.kind.matches('[')\n": "expression: error parsing regexp: missing closing ]",
+	}
+
+	for content, expected := range tests {
+		dir := t.TempDir()
+		writeRules(t, dir, "rules.yaml", content)
+
+		_, err := analyzer.LoadRules(dir)
+		assert.True(t, errors.Is(err, analyzer.ErrInvalidRule))
+		assert.Contains(t, err.Error(), expected)
+	}
+
+	dir := t.TempDir()
+	writeRules(t, dir, "rules.yaml", "rules:\n  - name: privileged-container\n    severity: low\n    expression: 'true'\n")
+	_, err := analyzer.NewRuleSet(dir)
+	assert.EqualError(t, err, "invalid policy rule: privileged-container of rules.yaml is already defined in builtin")
+}
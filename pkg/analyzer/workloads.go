@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"fmt"
+	"strings"
+)
+
+// podSpecPaths are the paths to the pod spec of the kinds running pods.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+const (
+	containersField          = "containers"
+	initContainersField      = "initContainers"
+	ephemeralContainersField = "ephemeralContainers"
+)
+
+// container is a container of a pod spec, Type is the field listing it and Path its JSON path in the resource.
+type container struct {
+	Name   string
+	Type   string
+	Path   string
+	Fields map[string]interface{}
+}
+
+// podSpec returns the pod spec of a workload and its JSON path, ok is false for kinds not running pods.
+func podSpec(object map[string]interface{}) (map[string]interface{}, string, bool) {
+	keys, ok := podSpecPaths[kubeobject.NestedString(object, "kind")]
+	if !ok {
+		return nil, "", false
+	}
+
+	spec, ok := kubeobject.NestedMap(object, keys...)
+	if !ok {
+		return nil, "", false
+	}
+
+	return spec, "$." + strings.Join(keys, "."), true
+}
+
+// podContainers returns the containers of the given container fields of a pod spec, in the order of the fields.
+func podContainers(spec map[string]interface{}, specPath string, fields ...string) []container {
+	var containers []container
+
+	for _, field := range fields {
+		items, _ := spec[field].([]interface{})
+		for i, item := range items {
+			containerFields, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			containers = append(containers, container{
+				Name:   kubeobject.NestedString(containerFields, "name"),
+				Type:   field,
+				Path:   fmt.Sprintf("%s.%s[%d]", specPath, field, i),
+				Fields: containerFields,
+			})
+		}
+	}
+
+	return containers
+}
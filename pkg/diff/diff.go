@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"chart-viewer/pkg/model"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	contextLines = 3
+	devNull      = "/dev/null"
+)
+
+// Templates compares the templates of two chart versions by file name. Only the files that were added,
+// removed or modified are returned, sorted by name, each with a unified diff labeled by version and file name.
+func Templates(from, to []model.Template, fromVersion, toVersion string) ([]model.FileDiff, error) {
+	fromContent := templateContents(from)
+	toContent := templateContents(to)
+
+	names := make([]string, 0, len(fromContent)+len(toContent))
+	for name := range fromContent {
+		names = append(names, name)
+	}
+	for name := range toContent {
+		if _, ok := fromContent[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	files := make([]model.FileDiff, 0)
+	for _, name := range names {
+		before, inFrom := fromContent[name]
+		after, inTo := toContent[name]
+
+		fileDiff := model.FileDiff{
+			Name:   name,
+			Status: model.DiffModified,
+		}
+		fromFile := fmt.Sprintf("%s/%s", fromVersion, name)
+		toFile := fmt.Sprintf("%s/%s", toVersion, name)
+
+		switch {
+		case !inFrom:
+			fileDiff.Status = model.DiffAdded
+			fromFile = devNull
+		case !inTo:
+			fileDiff.Status = model.DiffRemoved
+			toFile = devNull
+		case before == after:
+			continue
+		}
+
+		unified, err := Unified(before, after, fromFile, toFile)
+		if err != nil {
+			return nil, err
+		}
+		fileDiff.Diff = unified
+
+		files = append(files, fileDiff)
+	}
+
+	return files, nil
+}
+
+// Unified returns the unified diff between two texts, empty when they are equal.
+func Unified(before, after, fromFile, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(before),
+		B:        splitLines(after),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  contextLines,
+	})
+}
+
+// splitLines keeps the line endings, unlike difflib.SplitLines it adds no empty line after a trailing newline.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return lines
+}
+
+func templateContents(templates []model.Template) map[string]string {
+	contents := make(map[string]string, len(templates))
+	for _, t := range templates {
+		contents[t.Name] = t.Content
+	}
+
+	return contents
+}
@@ -0,0 +1,64 @@
+package diff_test
+
+import (
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	from := []model.Template{
+		{Name: "templates/deployment.yaml", Content: "kind: Deployment\nspec:\n  replicas: 1\n"},
+		{Name: "templates/ingress.yaml", Content: "kind: Ingress\n"},
+		{Name: "templates/service.yaml", Content: "kind: Service\n"},
+	}
+	to := []model.Template{
+		{Name: "templates/service.yaml", Content: "kind: Service\n"},
+		{Name: "templates/deployment.yaml", Content: "kind: Deployment\nspec:\n  replicas: 2\n"},
+		{Name: "templates/hpa.yaml", Content: "kind: HorizontalPodAutoscaler\n"},
+	}
+
+	files, err := diff.Templates(from, to, "0.1.0", "0.2.0")
+	assert.NoError(t, err)
+
+	expected := []model.FileDiff{
+		{
+			Name:   "templates/deployment.yaml",
+			Status: model.DiffModified,
+			Diff: "--- 0.1.0/templates/deployment.yaml\n" +
+				"+++ 0.2.0/templates/deployment.yaml\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" kind: Deployment\n" +
+				" spec:\n" +
+				"-  replicas: 1\n" +
+				"+  replicas: 2\n",
+		},
+		{
+			Name:   "templates/hpa.yaml",
+			Status: model.DiffAdded,
+			Diff: "--- /dev/null\n" +
+				"+++ 0.2.0/templates/hpa.yaml\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+kind: HorizontalPodAutoscaler\n",
+		},
+		{
+			Name:   "templates/ingress.yaml",
+			Status: model.DiffRemoved,
+			Diff: "--- 0.1.0/templates/ingress.yaml\n" +
+				"+++ /dev/null\n" +
+				"@@ -1 +0,0 @@\n" +
+				"-kind: Ingress\n",
+		},
+	}
+	assert.Equal(t, expected, files)
+}
+
+func TestTemplatesWithoutChanges(t *testing.T) {
+	templates := []model.Template{{Name: "templates/service.yaml", Content: "kind: Service\n"}}
+
+	files, err := diff.Templates(templates, templates, "0.1.0", "0.1.0")
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	assert.NotNil(t, files)
+}
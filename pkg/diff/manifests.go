@@ -0,0 +1,166 @@
+package diff
+
+import (
+	"chart-viewer/pkg/jsonpath"
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Manifests compares rendered manifests resource by resource. Resources are matched by apiVersion, kind,
+// namespace and name, so moving a resource to another file or reordering keys is not a change. Only added,
+// removed and modified resources are returned, modified ones with the changed fields. The resources that cannot be
+// matched, unnamed ones like those using generateName and the second of two with the same identity, are reported in
+// the warnings.
+func Manifests(from, to []model.Manifest, fromVersion, toVersion string) ([]model.ResourceDiff, []string, error) {
+	fromResources, warnings, err := parseResources(from, fromVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toResources, toWarnings, err := parseResources(to, toVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings = append(warnings, toWarnings...)
+
+	ids := make([]model.ResourceID, 0, len(fromResources)+len(toResources))
+	for id := range fromResources {
+		ids = append(ids, id)
+	}
+	for id := range toResources {
+		if _, ok := fromResources[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	resources := make([]model.ResourceDiff, 0)
+	for _, id := range ids {
+		before, inFrom := fromResources[id]
+		after, inTo := toResources[id]
+
+		switch {
+		case !inFrom:
+			resources = append(resources, model.ResourceDiff{Resource: id, Status: model.DiffAdded})
+		case !inTo:
+			resources = append(resources, model.ResourceDiff{Resource: id, Status: model.DiffRemoved})
+		default:
+			changes := compareValues(jsonpath.Root, before.object, after.object, nil)
+			if len(changes) != 0 {
+				resources = append(resources, model.ResourceDiff{Resource: id, Status: model.DiffModified, Changes: changes})
+			}
+		}
+	}
+
+	return resources, warnings, nil
+}
+
+type parsedResource struct {
+	manifest string
+	object   map[string]interface{}
+}
+
+// parseResources indexes the resources of the manifests of a version by identity, the first of two resources with
+// the same identity is kept.
+func parseResources(manifests []model.Manifest, version string) (map[model.ResourceID]parsedResource, []string, error) {
+	resources := make(map[model.ResourceID]parsedResource)
+	var warnings []string
+
+	for _, manifest := range manifests {
+		objects, err := kubeobject.Parse(manifest.Content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing %s: %w", manifest.Name, err)
+		}
+
+		for _, object := range objects {
+			id := kubeobject.ID(object)
+			if id.Name == "" {
+				warnings = append(warnings, fmt.Sprintf("%s: %s renders a %s %s without a name, it is not compared", version, manifest.Name, id.APIVersion, id.Kind))
+				continue
+			}
+
+			if first, ok := resources[id]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s: %s is rendered by %s and %s, only the first one is compared", version, id, first.manifest, manifest.Name))
+				continue
+			}
+			resources[id] = parsedResource{manifest: manifest.Name, object: object}
+		}
+	}
+
+	return resources, warnings, nil
+}
+
+func compareValues(path string, before, after interface{}, changes []model.FieldChange) []model.FieldChange {
+	switch beforeValue := before.(type) {
+	case map[string]interface{}:
+		afterValue, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		return compareMaps(path, beforeValue, afterValue, changes)
+	case []interface{}:
+		afterValue, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+		return compareLists(path, beforeValue, afterValue, changes)
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return changes
+	}
+
+	return append(changes, model.FieldChange{Path: path, Status: model.DiffChanged, Old: before, New: after})
+}
+
+func compareMaps(path string, before, after map[string]interface{}, changes []model.FieldChange) []model.FieldChange {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		beforeValue, inBefore := before[key]
+		afterValue, inAfter := after[key]
+		keyPath := jsonpath.Key(path, key)
+
+		switch {
+		case !inBefore:
+			changes = append(changes, model.FieldChange{Path: keyPath, Status: model.DiffAdded, New: afterValue})
+		case !inAfter:
+			changes = append(changes, model.FieldChange{Path: keyPath, Status: model.DiffRemoved, Old: beforeValue})
+		default:
+			changes = compareValues(keyPath, beforeValue, afterValue, changes)
+		}
+	}
+
+	return changes
+}
+
+func compareLists(path string, before, after []interface{}, changes []model.FieldChange) []model.FieldChange {
+	for i := 0; i < len(before) || i < len(after); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+
+		switch {
+		case i >= len(before):
+			changes = append(changes, model.FieldChange{Path: itemPath, Status: model.DiffAdded, New: after[i]})
+		case i >= len(after):
+			changes = append(changes, model.FieldChange{Path: itemPath, Status: model.DiffRemoved, Old: before[i]})
+		default:
+			changes = compareValues(itemPath, before[i], after[i], changes)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,140 @@
+package diff_test
+
+import (
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestManifests(t *testing.T) {
+	from := []model.Manifest{
+		{
+			Name: "deployment.yaml",
+			Content: `# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  labels:
+    app.kubernetes.io/name: app
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.16
+`,
+		},
+		{
+			Name: "resources.yaml",
+			Content: `apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  type: ClusterIP
+  ports:
+    - port: 80
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: app
+`,
+		},
+	}
+	to := []model.Manifest{
+		{
+			Name: "deployment.yaml",
+			Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels: {app.kubernetes.io/name: app, app.kubernetes.io/version: "2"}
+  namespace: default
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - image: nginx:1.17
+          name: app
+        - name: sidecar
+          image: envoy
+  replicas: 3
+`,
+		},
+		{
+			Name: "service.yaml",
+			Content: `apiVersion: v1
+kind: Service
+metadata:
+    name: app
+spec:
+    ports:
+        - port: 80
+    type:   ClusterIP
+`,
+		},
+		{
+			Name: "ingress.yaml",
+			Content: `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: app
+`,
+		},
+	}
+
+	resources, warnings, err := diff.Manifests(from, to, "0.1.0", "0.2.0")
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	expected := []model.ResourceDiff{
+		{
+			Resource: model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "app"},
+			Status:   model.DiffModified,
+			Changes: []model.FieldChange{
+				{Path: "$.metadata.labels['app.kubernetes.io/version']", Status: model.DiffAdded, New: "2"},
+				{Path: "$.spec.replicas", Status: model.DiffChanged, Old: 2, New: 3},
+				{Path: "$.spec.template.spec.containers[0].image", Status: model.DiffChanged, Old: "nginx:1.16", New: "nginx:1.17"},
+				{Path: "$.spec.template.spec.containers[1]", Status: model.DiffAdded, New: map[string]interface{}{"name": "sidecar", "image": "envoy"}},
+			},
+		},
+		{
+			Resource: model.ResourceID{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app"},
+			Status:   model.DiffRemoved,
+		},
+		{
+			Resource: model.ResourceID{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "app"},
+			Status:   model.DiffAdded,
+		},
+	}
+	assert.Equal(t, expected, resources)
+}
+
+func TestManifestsInvalidYAML(t *testing.T) {
+	_, _, err := diff.Manifests([]model.Manifest{{Name: "broken.yaml", Content: "kind: [Deployment"}}, nil, "0.1.0", "0.2.0")
+	assert.Error(t, err)
+}
+
+func TestManifestsUnnamedAndDuplicateResources(t *testing.T) {
+	from := []model.Manifest{
+		{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  mode: a\n"},
+	}
+	to := []model.Manifest{
+		{Name: "configmap.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  mode: a\n"},
+		{Name: "overrides.yaml", Content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  mode: b\n"},
+		{Name: "job.yaml", Content: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  generateName: migrate-\n"},
+	}
+
+	resources, warnings, err := diff.Manifests(from, to, "0.1.0", "0.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ResourceDiff{}, resources)
+	assert.Equal(t, []string{
+		"0.2.0: v1 ConfigMap app is rendered by configmap.yaml and overrides.yaml, only the first one is compared",
+		"0.2.0: job.yaml renders a batch/v1 Job without a name, it is not compared",
+	}, warnings)
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"chart-viewer/pkg/model"
+	"strings"
+)
+
+// RBAC compares the permission matrices of two chart versions. Rows are matched by scope, role, API group, resource
+// and resource names, a matched row is modified when its verbs or subjects changed. Changes keep the order of the
+// matrices, removed rows first.
+func RBAC(from, to []model.RBACPermission) []model.RBACPermissionChange {
+	toPermissions := make(map[string]model.RBACPermission, len(to))
+	for _, permission := range to {
+		toPermissions[permissionKey(permission)] = permission
+	}
+
+	changes := make([]model.RBACPermissionChange, 0)
+	fromKeys := make(map[string]bool, len(from))
+	for _, old := range from {
+		key := permissionKey(old)
+		fromKeys[key] = true
+
+		permission, ok := toPermissions[key]
+		if !ok {
+			changes = append(changes, model.RBACPermissionChange{Status: model.DiffRemoved, Permission: old})
+			continue
+		}
+
+		change := model.RBACPermissionChange{
+			Status:          model.DiffModified,
+			Permission:      permission,
+			AddedVerbs:      missing(permission.Verbs, old.Verbs),
+			RemovedVerbs:    missing(old.Verbs, permission.Verbs),
+			AddedSubjects:   missing(permission.Subjects, old.Subjects),
+			RemovedSubjects: missing(old.Subjects, permission.Subjects),
+		}
+		if len(change.AddedVerbs)+len(change.RemovedVerbs)+len(change.AddedSubjects)+len(change.RemovedSubjects) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, permission := range to {
+		if !fromKeys[permissionKey(permission)] {
+			changes = append(changes, model.RBACPermissionChange{Status: model.DiffAdded, Permission: permission})
+		}
+	}
+
+	return changes
+}
+
+func permissionKey(permission model.RBACPermission) string {
+	return strings.Join([]string{
+		permission.Scope,
+		permission.Role,
+		permission.APIGroup,
+		permission.Resource,
+		strings.Join(permission.ResourceNames, ","),
+	}, "|")
+}
+
+// missing returns the values that are not in others.
+func missing(values, others []string) []string {
+	set := make(map[string]bool, len(others))
+	for _, value := range others {
+		set[value] = true
+	}
+
+	var result []string
+	for _, value := range values {
+		if !set[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package diff_test
+
+import (
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRBAC(t *testing.T) {
+	operator := []string{"ServiceAccount apps/operator"}
+	from := []model.RBACPermission{
+		{Scope: "cluster", Resource: "pods", Verbs: []string{"get", "list"}, Role: "ClusterRole operator", Subjects: operator},
+		{Scope: "cluster", Resource: "secrets", Verbs: []string{"get"}, Role: "ClusterRole operator", Subjects: operator},
+		{Scope: "apps", APIGroup: "coordination.k8s.io", Resource: "leases", Verbs: []string{"get"}, Role: "Role apps/leader-election", Subjects: operator},
+	}
+	to := []model.RBACPermission{
+		{Scope: "cluster", Resource: "pods", Verbs: []string{"get", "list", "watch"}, Role: "ClusterRole operator", Subjects: []string{"Group admins"}},
+		{Scope: "cluster", APIGroup: "apps", Resource: "*", Verbs: []string{"*"}, Role: "ClusterRole operator", Subjects: operator, Wildcard: true},
+		{Scope: "apps", APIGroup: "coordination.k8s.io", Resource: "leases", Verbs: []string{"get"}, Role: "Role apps/leader-election", Subjects: operator},
+	}
+
+	assert.Equal(t, []model.RBACPermissionChange{
+		{
+			Status:          model.DiffModified,
+			Permission:      to[0],
+			AddedVerbs:      []string{"watch"},
+			AddedSubjects:   []string{"Group admins"},
+			RemovedSubjects: operator,
+		},
+		{Status: model.DiffRemoved, Permission: from[1]},
+		{Status: model.DiffAdded, Permission: to[1]},
+	}, diff.RBAC(from, to))
+}
+
+func TestRBACWithoutChanges(t *testing.T) {
+	permissions := []model.RBACPermission{{Scope: "cluster", Resource: "pods", Verbs: []string{"get"}, Role: "ClusterRole operator"}}
+
+	assert.Equal(t, []model.RBACPermissionChange{}, diff.RBAC(permissions, permissions))
+}
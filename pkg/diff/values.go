@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"chart-viewer/pkg/model"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var setKeyEscaper = strings.NewReplacer(`.`, `\.`)
+
+// Values compares the default values of two chart versions. Maps are compared key by key while lists and empty
+// maps are compared as a whole. A removed and an added value are reported as renamed when they hold the same value
+// and either share the last key, like image.tag moving to controller.image.tag, or the value is a string held
+// by no other removed or added key.
+func Values(from, to map[string]interface{}) []model.ValueChange {
+	fromValues := flattenValues("", from, map[string]interface{}{})
+	toValues := flattenValues("", to, map[string]interface{}{})
+
+	var removed, added []string
+	changes := make([]model.ValueChange, 0)
+
+	for path, old := range fromValues {
+		value, ok := toValues[path]
+		switch {
+		case !ok:
+			removed = append(removed, path)
+		case !reflect.DeepEqual(old, value):
+			changes = append(changes, model.ValueChange{Path: path, Status: model.DiffChanged, Old: old, New: value})
+		}
+	}
+	for path := range toValues {
+		if _, ok := fromValues[path]; !ok {
+			added = append(added, path)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	renamed := findRenames(removed, added, fromValues, toValues)
+	for _, oldPath := range removed {
+		if path, ok := renamed[oldPath]; ok {
+			changes = append(changes, model.ValueChange{Path: path, OldPath: oldPath, Status: model.DiffRenamed, Old: fromValues[oldPath], New: toValues[path]})
+			continue
+		}
+		changes = append(changes, model.ValueChange{Path: oldPath, Status: model.DiffRemoved, Old: fromValues[oldPath]})
+	}
+
+	renamedTo := make(map[string]bool, len(renamed))
+	for _, path := range renamed {
+		renamedTo[path] = true
+	}
+	for _, path := range added {
+		if !renamedTo[path] {
+			changes = append(changes, model.ValueChange{Path: path, Status: model.DiffAdded, New: toValues[path]})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// findRenames maps removed paths to the added path they were most likely renamed to.
+func findRenames(removed, added []string, fromValues, toValues map[string]interface{}) map[string]string {
+	renamed := make(map[string]string)
+	taken := make(map[string]bool)
+
+	for _, oldPath := range removed {
+		for _, path := range added {
+			if !taken[path] && lastKey(oldPath) == lastKey(path) && reflect.DeepEqual(fromValues[oldPath], toValues[path]) {
+				renamed[oldPath] = path
+				taken[path] = true
+				break
+			}
+		}
+	}
+
+	for _, oldPath := range removed {
+		if _, ok := renamed[oldPath]; ok {
+			continue
+		}
+
+		value, ok := fromValues[oldPath].(string)
+		if !ok || value == "" || countValue(removed, fromValues, value) != 1 || countValue(added, toValues, value) != 1 {
+			continue
+		}
+
+		for _, path := range added {
+			if !taken[path] && toValues[path] == value {
+				renamed[oldPath] = path
+				taken[path] = true
+				break
+			}
+		}
+	}
+
+	return renamed
+}
+
+func countValue(paths []string, values map[string]interface{}, value string) int {
+	count := 0
+	for _, path := range paths {
+		if values[path] == value {
+			count++
+		}
+	}
+
+	return count
+}
+
+func lastKey(path string) string {
+	keys := splitPath(path)
+	return keys[len(keys)-1]
+}
+
+// splitPath splits a path on the dots that are not escaped.
+func splitPath(path string) []string {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case path[i] == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+
+	return append(keys, key.String())
+}
+
+func flattenValues(prefix string, values map[string]interface{}, flattened map[string]interface{}) map[string]interface{} {
+	for key, value := range values {
+		path := setKeyEscaper.Replace(key)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		nested, ok := value.(map[string]interface{})
+		if ok && len(nested) != 0 {
+			flattenValues(path, nested, flattened)
+			continue
+		}
+		flattened[path] = value
+	}
+
+	return flattened
+}
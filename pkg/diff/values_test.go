@@ -0,0 +1,60 @@
+package diff_test
+
+import (
+	"chart-viewer/pkg/diff"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	from := map[string]interface{}{
+		"replicaCount": float64(1),
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.16.0",
+		},
+		"serviceAccountName": "default-sa",
+		"podAnnotations": map[string]interface{}{
+			"prometheus.io/scrape": "true",
+		},
+		"tolerations": []interface{}{},
+		"ingress":     map[string]interface{}{"enabled": false},
+	}
+	to := map[string]interface{}{
+		"replicaCount": float64(2),
+		"controller": map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "1.16.0",
+			},
+		},
+		"serviceAccount": map[string]interface{}{
+			"name": "default-sa",
+		},
+		"podAnnotations": map[string]interface{}{
+			"prometheus.io/scrape": "false",
+		},
+		"tolerations": []interface{}{},
+		"affinity":    map[string]interface{}{},
+	}
+
+	expected := []model.ValueChange{
+		{Path: "affinity", Status: model.DiffAdded, New: map[string]interface{}{}},
+		{Path: "controller.image.repository", OldPath: "image.repository", Status: model.DiffRenamed, Old: "nginx", New: "nginx"},
+		{Path: "controller.image.tag", OldPath: "image.tag", Status: model.DiffRenamed, Old: "1.16.0", New: "1.16.0"},
+		{Path: "ingress.enabled", Status: model.DiffRemoved, Old: false},
+		{Path: `podAnnotations.prometheus\.io/scrape`, Status: model.DiffChanged, Old: "true", New: "false"},
+		{Path: "replicaCount", Status: model.DiffChanged, Old: float64(1), New: float64(2)},
+		{Path: "serviceAccount.name", OldPath: "serviceAccountName", Status: model.DiffRenamed, Old: "default-sa", New: "default-sa"},
+	}
+	assert.Equal(t, expected, diff.Values(from, to))
+}
+
+func TestValuesWithoutChanges(t *testing.T) {
+	values := map[string]interface{}{"image": map[string]interface{}{"tag": "1.16.0"}}
+
+	changes := diff.Values(values, values)
+	assert.Empty(t, changes)
+	assert.NotNil(t, changes)
+}
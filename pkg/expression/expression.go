@@ -0,0 +1,61 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/interpreter"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+)
+
+// Program is a compiled CEL expression, safe for concurrent use.
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+// Compile parses and checks a CEL expression over the given variables, referencing another variable is an error.
+// The variables may hold any value. The literal patterns of matches are compiled here, an invalid one is an error.
+func Compile(source string, variables ...string) (*Program, error) {
+	declarations := make([]*exprpb.Decl, 0, len(variables))
+	for _, variable := range variables {
+		declarations = append(declarations, decls.NewVar(variable, decls.Dyn))
+	}
+
+	env, err := cel.NewEnv(cel.Declarations(declarations...))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(source)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	program, err := env.Program(ast, cel.OptimizeRegex(interpreter.MatchesRegexOptimization))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{source: source, program: program}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// EvalBool evaluates an expression that must result in a bool.
+func (p *Program) EvalBool(variables map[string]interface{}) (bool, error) {
+	value, _, err := p.program.Eval(variables)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression results in %s, not a bool", value.Type().TypeName())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package expression_test
+
+import (
+	"chart-viewer/pkg/expression"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+    team: payments
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: registry.local/web:1.2.0
+        - name: proxy
+          image: docker.io/envoyproxy/envoy:v1.28
+`
+
+func TestProgram_EvalBool(t *testing.T) {
+	var object map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(deployment), &object))
+	variables := map[string]interface{}{"object": object}
+
+	tests := map[string]bool{
+		`object.kind == "Deployment" && object.spec.replicas >= 2`:                           true,
+		`object.metadata.labels["app.kubernetes.io/name"] == "web"`:                          true,
+		`has(object.metadata.labels.team)`:                                                   true,
+		`has(object.metadata.labels.owner)`:                                                  false,
+		`object.metadata.labels.team in ["payments", "search"]`:                              true,
+		`object.spec.template.spec.containers.all(c, c.image.startsWith("registry.local/"))`: false,
+		`object.spec.template.spec.containers.exists(c, c.image.matches("^docker\\.io/"))`:   true,
+		`object.spec.template.spec.containers.exists(c, c.image.matches(object.kind))`:       false,
+		`size(object.spec.template.spec.containers.filter(c, c.name != "web")) == 1`:         true,
+		`"replicas: " + string(object.spec.replicas) == "replicas: 3"`:                       true,
+	}
+
+	for source, expected := range tests {
+		program, err := expression.Compile(source, "object")
+		if assert.NoError(t, err, source) {
+			value, err := program.EvalBool(variables)
+			assert.NoError(t, err, source)
+			assert.Equal(t, expected, value, source)
+		}
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		`object.kind ==`:                      "Syntax error: mismatched input '<EOF>'",
+		`objects.kind == "Pod"`:               "undeclared reference to 'objects'",
+		`object.metadata.name.lower()`:        "undeclared reference to 'lower'",
+		`object.metadata.name.matches("[a-")`: "error parsing regexp: missing closing ]: `[a-`",
+	}
+
+	for source, expected := range tests {
+		_, err := expression.Compile(source, "object")
+		if assert.Error(t, err, source) {
+			assert.Contains(t, err.Error(), expected, source)
+		}
+	}
+}
+
+func TestProgram_EvalBoolErrors(t *testing.T) {
+	variables := map[string]interface{}{"object": map[string]interface{}{"kind": "Service", "ports": []interface{}{80}}}
+
+	tests := map[string]string{
+		`object.spec.type == "NodePort"`:         "no such key: spec",
+		`object.ports[2] == 80`:                  "index out of bounds: 2",
+		`object.kind`:                            "expression results in string, not a bool",
+		`object.kind.matches(object.kind + "[")`: "error parsing regexp: missing closing ]: `[`",
+	}
+
+	for source, expected := range tests {
+		program, err := expression.Compile(source, "object")
+		if assert.NoError(t, err, source) {
+			_, err = program.EvalBool(variables)
+			assert.EqualError(t, err, expected, source)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// TestHelm_RenderManifestConcurrently renders different charts with different options through one client,
+// run it with -race to catch state shared between renders.
+func TestHelm_RenderManifestConcurrently(t *testing.T) {
+	server := newChartServer(t, func(r *http.Request) bool { return true })
+	defer server.Close()
+
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	remoteRepo := model.Repo{Name: "remote", URL: server.URL, InsecureSkipTLSVerify: true}
+
+	sample, err := h.UploadChart(packageChart(t, "testdata/sample"))
+	assert.NoError(t, err)
+	release, err := h.UploadChart(packageChart(t, "testdata/release"))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 24; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			releaseName := fmt.Sprintf("release-%d", i)
+			renderOptions := model.RenderOptions{
+				ValuesOptions: model.ValuesOptions{Set: []string{fmt.Sprintf("replicaCount=%d", i)}},
+				ReleaseName:   releaseName,
+				Namespace:     fmt.Sprintf("namespace-%d", i),
+				KubeVersion:   fmt.Sprintf("1.%d", i),
+			}
+
+			switch i % 3 {
+			case 0:
+				err, manifests := h.RenderManifest(uploadsRepo, sample.ID, "0.1.0", renderOptions)
+				assert.NoError(t, err)
+				assert.Contains(t, manifestContent(manifests, "deployment.yaml"), fmt.Sprintf("replicas: %d\n", i))
+				assert.Contains(t, manifestContent(manifests, "deployment.yaml"), fmt.Sprintf("name: %s-sample\n", releaseName))
+			case 1:
+				err, manifests := h.RenderManifest(remoteRepo, "sample", "0.1.0", renderOptions)
+				assert.NoError(t, err)
+				assert.Contains(t, manifestContent(manifests, "deployment.yaml"), fmt.Sprintf("replicas: %d\n", i))
+				assert.Contains(t, manifestContent(manifests, "deployment.yaml"), fmt.Sprintf("name: %s-sample\n", releaseName))
+			case 2:
+				err, manifests := h.RenderManifest(uploadsRepo, release.ID, "0.1.0", renderOptions)
+				assert.NoError(t, err)
+				assert.Contains(t, manifestContent(manifests, "configmap.yaml"), fmt.Sprintf("name: %s\n", releaseName))
+				assert.Contains(t, manifestContent(manifests, "configmap.yaml"), fmt.Sprintf("namespace: namespace-%d\n", i))
+				assert.Contains(t, manifestContent(manifests, "configmap.yaml"), fmt.Sprintf(`kubeVersion: "v1.%d.0"`, i))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func manifestContent(manifests []model.Manifest, name string) string {
+	for _, manifest := range manifests {
+		if manifest.Name == name {
+			return manifest.Content
+		}
+	}
+
+	return ""
+}
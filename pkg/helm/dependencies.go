@@ -0,0 +1,93 @@
+package helm
+
+import (
+	"chart-viewer/pkg/model"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// GetDependencies returns the dependency tree of the chart with the default values and templates of every subchart.
+func (h helm) GetDependencies(repo model.Repo, chartName, chartVersion string) ([]model.Subchart, error) {
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return subcharts(chartRequested, ""), nil
+}
+
+// subcharts pairs the dependencies declared in Chart.yaml with the charts packaged in charts/. A chart declared
+// under several aliases is listed once per alias, packaged charts that are not declared are listed by name.
+func subcharts(c *chart.Chart, parentScope string) []model.Subchart {
+	packaged := make(map[string]*chart.Chart)
+	for _, dependency := range c.Dependencies() {
+		packaged[dependency.Name()] = dependency
+	}
+
+	result := make([]model.Subchart, 0)
+	declared := make(map[string]bool)
+
+	for _, dependency := range c.Metadata.Dependencies {
+		if dependency == nil {
+			continue
+		}
+		declared[dependency.Name] = true
+
+		scope := dependency.Name
+		if dependency.Alias != "" {
+			scope = dependency.Alias
+		}
+
+		subchart := model.Subchart{
+			Name:        dependency.Name,
+			Version:     dependency.Version,
+			Repository:  dependency.Repository,
+			Condition:   dependency.Condition,
+			Tags:        dependency.Tags,
+			Alias:       dependency.Alias,
+			ValuesScope: valuesScope(parentScope, scope),
+			Missing:     packaged[dependency.Name] == nil,
+		}
+
+		if packagedChart, ok := packaged[dependency.Name]; ok {
+			subchart = withPackagedChart(subchart, packagedChart)
+		}
+		result = append(result, subchart)
+	}
+
+	for _, packagedChart := range c.Dependencies() {
+		if declared[packagedChart.Name()] {
+			continue
+		}
+
+		result = append(result, withPackagedChart(model.Subchart{
+			Name:        packagedChart.Name(),
+			ValuesScope: valuesScope(parentScope, packagedChart.Name()),
+		}, packagedChart))
+	}
+
+	return result
+}
+
+func withPackagedChart(subchart model.Subchart, c *chart.Chart) model.Subchart {
+	subchart.Version = c.Metadata.Version
+	subchart.Values = c.Values
+	subchart.Dependencies = subcharts(c, subchart.ValuesScope)
+
+	for _, t := range c.Templates {
+		subchart.Templates = append(subchart.Templates, model.Template{
+			Name:    t.Name,
+			Content: string(t.Data),
+		})
+	}
+
+	return subchart
+}
+
+func valuesScope(parentScope, scope string) string {
+	if parentScope == "" {
+		return scope
+	}
+
+	return parentScope + "." + scope
+}
@@ -0,0 +1,82 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"io/ioutil"
+	"testing"
+)
+
+func TestHelm_GetDependencies(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChartWithDependencies(t, "testdata/umbrella", "testdata/sample"))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	dependencies, err := h.GetDependencies(uploadsRepo, uploaded.ID, "0.1.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, dependencies, 1)
+	subchart := dependencies[0]
+	assert.Equal(t, "sample", subchart.Name)
+	assert.Equal(t, "0.1.0", subchart.Version)
+	assert.Equal(t, "sample.enabled", subchart.Condition)
+	assert.Equal(t, []string{"frontend"}, subchart.Tags)
+	assert.Equal(t, "web", subchart.Alias)
+	assert.Equal(t, "web", subchart.ValuesScope)
+	assert.False(t, subchart.Missing)
+	assert.Equal(t, float64(1), subchart.Values["replicaCount"])
+
+	var templateNames []string
+	for _, template := range subchart.Templates {
+		templateNames = append(templateNames, template.Name)
+	}
+	assert.ElementsMatch(t, []string{"templates/_helpers.tpl", "templates/deployment.yaml", "templates/service.yaml"}, templateNames)
+}
+
+func TestHelm_GetDependenciesNotPackaged(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/umbrella"))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	dependencies, err := h.GetDependencies(uploadsRepo, uploaded.ID, "0.1.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []model.Subchart{
+		{
+			Name:        "sample",
+			Version:     "0.1.0",
+			Repository:  "file://../sample",
+			Condition:   "sample.enabled",
+			Tags:        []string{"frontend"},
+			Alias:       "web",
+			ValuesScope: "web",
+			Missing:     true,
+		},
+	}, dependencies)
+}
+
+// packageChartWithDependencies packages the chart at path with the charts at dependencyPaths in its charts/ directory.
+func packageChartWithDependencies(t *testing.T, path string, dependencyPaths ...string) []byte {
+	c, err := loader.Load(path)
+	assert.NoError(t, err)
+
+	for _, dependencyPath := range dependencyPaths {
+		dependency, err := loader.Load(dependencyPath)
+		assert.NoError(t, err)
+		c.AddDependency(dependency)
+	}
+
+	archivePath, err := chartutil.Save(c, t.TempDir())
+	assert.NoError(t, err)
+
+	archive, err := ioutil.ReadFile(archivePath)
+	assert.NoError(t, err)
+
+	return archive
+}
@@ -0,0 +1,197 @@
+package helm
+
+import (
+	"bytes"
+	"chart-viewer/pkg/model"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// loadChart loads the chart version of the repo, downloading its archive unless it was uploaded.
+func (h helm) loadChart(repo model.Repo, chartName, chartVersion string) (*chart.Chart, error) {
+	chartRequested, _, err := h.loadChartEntry(repo, chartName, chartVersion)
+	return chartRequested, err
+}
+
+// loadChartEntry loads the chart version along with its entry in the repo index. Only classic repos have an index,
+// the entry of uploaded and OCI charts only holds the name and version.
+func (h helm) loadChartEntry(repo model.Repo, chartName, chartVersion string) (*chart.Chart, model.ChartResponse, error) {
+	entry := model.ChartResponse{Name: chartName, Version: chartVersion}
+
+	if repo.Type == model.RepoTypeUpload {
+		// uploads are addressed by the sha256 digest of their archive, like the digest of an index entry
+		entry.Digest = chartName
+		chartRequested, err := h.loadUploadedChart(chartName, chartVersion)
+		return chartRequested, entry, err
+	}
+
+	if repo.IsOCI() {
+		client, err := h.newOCIClient(repo)
+		if err != nil {
+			return nil, entry, err
+		}
+
+		archive, err := client.pullChart(chartName, chartVersion)
+		if err != nil {
+			return nil, entry, err
+		}
+
+		chartRequested, err := loader.LoadArchive(bytes.NewReader(archive))
+		return chartRequested, entry, err
+	}
+
+	client, err := h.clients.get(repo)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	index, err := h.getCachedIndex(client, repo)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	entry, err = findChartEntry(repo, index, chartName, chartVersion)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	chartUrl, err := chartURL(repo, entry)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	archive, err := download(client, chartUrl)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	chartRequested, err := loader.LoadArchive(bytes.NewReader(archive))
+	return chartRequested, entry, err
+}
+
+// getCachedIndex returns the index of the repo, it is only downloaded when it is not cached or has expired.
+func (h helm) getCachedIndex(client *http.Client, repo model.Repo) (model.RepoDetailResponse, error) {
+	if index, ok := h.indexes.get(repo); ok {
+		return index, nil
+	}
+
+	index, err := getIndex(client, repo)
+	if err != nil {
+		return index, err
+	}
+
+	h.indexes.set(repo, index)
+	return index, nil
+}
+
+func getIndex(client *http.Client, repo model.Repo) (model.RepoDetailResponse, error) {
+	var repoDetail model.RepoDetailResponse
+
+	indexUrl := strings.TrimSuffix(repo.URL, "/") + "/index.yaml"
+	content, err := download(client, indexUrl)
+	if err != nil {
+		return repoDetail, err
+	}
+
+	err = yaml.Unmarshal(content, &repoDetail)
+	if err != nil {
+		return repoDetail, fmt.Errorf("parsing %s: %w", indexUrl, err)
+	}
+
+	return repoDetail, nil
+}
+
+func findChartEntry(repo model.Repo, index model.RepoDetailResponse, chartName, chartVersion string) (model.ChartResponse, error) {
+	for _, c := range index.Entries[chartName] {
+		if c.Version == chartVersion {
+			return c, nil
+		}
+	}
+
+	return model.ChartResponse{}, fmt.Errorf("chart %s:%s not found in repo %s", chartName, chartVersion, repo.Name)
+}
+
+// chartURL returns the absolute archive URL of the index entry, relative index URLs are resolved against the repo URL.
+func chartURL(repo model.Repo, entry model.ChartResponse) (string, error) {
+	if len(entry.URLs) == 0 {
+		return "", fmt.Errorf("chart %s:%s of repo %s has no download url", entry.Name, entry.Version, repo.Name)
+	}
+
+	base, err := url.Parse(strings.TrimSuffix(repo.URL, "/") + "/")
+	if err != nil {
+		return "", err
+	}
+
+	ref, err := url.Parse(entry.URLs[0])
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(ref).String(), nil
+}
+
+func download(client *http.Client, downloadUrl string) ([]byte, error) {
+	log.Printf("out going call: %s\n", downloadUrl)
+	response, err := client.Get(downloadUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s returned %s", downloadUrl, response.Status)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
+// indexCache keeps the parsed index of each repo, so that loading the values, templates and metadata of a chart
+// does not download the index every time. A zero ttl keeps an index until GetIndex fetches it again.
+type indexCache struct {
+	ttl     time.Duration
+	mutex   sync.Mutex
+	entries map[string]cachedIndex
+}
+
+type cachedIndex struct {
+	index     model.RepoDetailResponse
+	fetchedAt time.Time
+}
+
+func newIndexCache(ttl time.Duration) *indexCache {
+	return &indexCache{ttl: ttl, entries: map[string]cachedIndex{}}
+}
+
+// indexCacheKey includes the url, an updated repo does not use the index of its previous url.
+func indexCacheKey(repo model.Repo) string {
+	return repo.Name + " " + repo.URL
+}
+
+func (c *indexCache) get(repo model.Repo) (model.RepoDetailResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.entries[indexCacheKey(repo)]
+	if !ok || (c.ttl > 0 && time.Since(cached.fetchedAt) > c.ttl) {
+		return model.RepoDetailResponse{}, false
+	}
+
+	return cached.index, true
+}
+
+func (c *indexCache) set(repo model.Repo, index model.RepoDetailResponse) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[indexCacheKey(repo)] = cachedIndex{index: index, fetchedAt: time.Now()}
+}
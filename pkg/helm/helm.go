@@ -10,74 +10,92 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
-	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/cli/values"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/releaseutil"
 )
 
+// Helm loads and renders charts. Every call works on its own chart and action configuration,
+// so a client is safe for concurrent use.
 type Helm interface {
-	GetValues(chartUrl, chartName, chartVersion string) (error, map[string]interface{})
-	GetManifest(chartUrl, chartName, chartVersion string) ([]model.Template, error)
-	RenderManifest(chartUrl, chartName, chartVersion string, files []string) (error, []model.Manifest)
+	GetIndex(repo model.Repo) (model.RepoDetailResponse, error)
+	GetValues(repo model.Repo, chartName, chartVersion string) (error, map[string]interface{})
+	GetManifest(repo model.Repo, chartName, chartVersion string) ([]model.Template, error)
+	RenderManifest(repo model.Repo, chartName, chartVersion string, renderOptions model.RenderOptions) (error, []model.Manifest)
+	UploadChart(archive []byte) (model.UploadedChart, error)
+	GetValuesSchema(repo model.Repo, chartName, chartVersion string) ([]byte, error)
+	ValidateValues(repo model.Repo, chartName, chartVersion string, valuesOptions model.ValuesOptions) ([]model.SchemaError, error)
+	GetChartMetadata(repo model.Repo, chartName, chartVersion string) (model.ChartMetadata, error)
+	GetDependencies(repo model.Repo, chartName, chartVersion string) ([]model.Subchart, error)
 }
 
 type helm struct {
-	client     *action.Install
 	repository repository.Repository
+	indexes    *indexCache
+	clients    *clientCache
 }
 
-var settings = cli.New()
+var (
+	settings = cli.New()
 
-func debug(format string, v ...interface{}) {}
-
-func NewHelmClient(repository repository.Repository) Helm {
-	actionConfig := new(action.Configuration)
-	err := actionConfig.Init(settings.RESTClientGetter(), settings.Namespace(), "", debug)
-	if err != nil {
-		panic(err)
-	}
+	manifestSourceRegex = regexp.MustCompile("# Source: (.+)")
+)
 
-	client := action.NewInstall(actionConfig)
-	client.DryRun = true
-	client.ClientOnly = true
-	client.UseReleaseName = true
+func debug(format string, v ...interface{}) {}
 
+// NewHelmClient returns a client storing uploaded charts in the repository. The index of a repo is downloaded
+// again once it is older than indexTTL, a zero indexTTL keeps it until the next GetIndex.
+func NewHelmClient(repository repository.Repository, indexTTL time.Duration) Helm {
 	return &helm{
-		client:     client,
 		repository: repository,
+		indexes:    newIndexCache(indexTTL),
+		clients:    newClientCache(),
 	}
 }
 
-func (h helm) GetValues(chartUrl, chartName, chartVersion string) (error, map[string]interface{}) {
-	log.Printf("getting %s:%s from remote\n", chartName, chartVersion)
+func (h helm) GetIndex(repo model.Repo) (model.RepoDetailResponse, error) {
+	if repo.Type == model.RepoTypeUpload {
+		return h.getUploadIndex()
+	}
 
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
+	if repo.IsOCI() {
+		client, err := h.newOCIClient(repo)
+		if err != nil {
+			return model.RepoDetailResponse{}, err
+		}
+
+		return client.getIndex()
+	}
+
+	client, err := h.clients.get(repo)
 	if err != nil {
-		return err, nil
+		return model.RepoDetailResponse{}, err
 	}
 
-	chartRequested, _ := loader.Load(cp)
+	// the index is always downloaded here, listing the charts of a repo refreshes the index its charts are loaded with
+	index, err := getIndex(client, repo)
+	if err != nil {
+		return index, err
+	}
 
-	return nil, chartRequested.Values
+	h.indexes.set(repo, index)
+	return index, nil
 }
 
-func (h helm) GetManifest(chartUrl, chartName, chartVersion string) ([]model.Template, error) {
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
+func (h helm) GetValues(repo model.Repo, chartName, chartVersion string) (error, map[string]interface{}) {
+	log.Printf("getting %s:%s from remote\n", chartName, chartVersion)
+
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
 	if err != nil {
-		return nil, err
+		return err, nil
 	}
 
-	chartRequested, err := loader.Load(cp)
+	return nil, chartRequested.Values
+}
+
+func (h helm) GetManifest(repo model.Repo, chartName, chartVersion string) ([]model.Template, error) {
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -96,28 +114,31 @@ func (h helm) GetManifest(chartUrl, chartName, chartVersion string) ([]model.Tem
 	return templateStrings, nil
 }
 
-func (h helm) RenderManifest(chartUrl, chartName, chartVersion string, files []string) (error, []model.Manifest) {
-	h.client.ChartPathOptions.Version = chartVersion
-	h.client.ReleaseName = chartName
-	h.client.RepoURL = chartUrl
-	cp, err := h.client.ChartPathOptions.LocateChart(chartName, settings)
+func (h helm) RenderManifest(repo model.Repo, chartName, chartVersion string, renderOptions model.RenderOptions) (error, []model.Manifest) {
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
 	if err != nil {
 		return err, nil
 	}
 
-	chartRequested, err := loader.Load(cp)
+	client, err := newInstall(chartRequested.Name(), renderOptions)
+	if err != nil {
+		return err, nil
+	}
 
-	valueOption := &values.Options{
-		ValueFiles: files,
+	vals, err := mergeValues(renderOptions.ValuesOptions)
+	if err != nil {
+		return err, nil
 	}
 
-	p := getter.All(settings)
-	vals, err := valueOption.MergeValues(p)
+	schemaErrors, err := validateValues(chartRequested, vals)
 	if err != nil {
 		return err, nil
 	}
+	if len(schemaErrors) != 0 {
+		return &InvalidValuesError{Errors: schemaErrors}, nil
+	}
 
-	rel, err := h.client.Run(chartRequested, vals)
+	rel, err := client.Run(chartRequested, vals)
 	if err != nil {
 		return err, nil
 	}
@@ -125,7 +146,7 @@ func (h helm) RenderManifest(chartUrl, chartName, chartVersion string, files []s
 	var manifests bytes.Buffer
 	fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
 
-	if !h.client.DisableHooks {
+	if !client.DisableHooks {
 		for _, h := range rel.Hooks {
 			fmt.Fprintln(&manifests, fmt.Sprintf("---\n # Source: %s\n%s", h.Path, h.Manifest))
 		}
@@ -144,13 +165,20 @@ func (h helm) RenderManifest(chartUrl, chartName, chartVersion string, files []s
 	for _, manifestKey := range manifestsKeys {
 		manifest := splitManifests[manifestKey]
 
-		manifestNameRegex := regexp.MustCompile("# Source: [^/]+/(.+)")
-		submatch := manifestNameRegex.FindStringSubmatch(manifest)
+		submatch := manifestSourceRegex.FindStringSubmatch(manifest)
 		if len(submatch) == 0 {
 			continue
 		}
+		// templates are sourced from {chart}/templates/..., the CRDs included on request from crds/...
 		manifestName := submatch[1]
-		manifestPath := filepath.Join(strings.Split(manifestName, "/")[1:]...)
+		manifestPath := manifestName
+		if !strings.HasPrefix(manifestName, "crds/") {
+			sourceSplit := strings.Split(manifestName, "/")
+			if len(sourceSplit) < 3 {
+				continue
+			}
+			manifestPath = filepath.Join(sourceSplit[2:]...)
+		}
 		manifestPathSplit := strings.Split(manifestPath, "/")
 
 		if manifestPathSplit[0] == "tests" {
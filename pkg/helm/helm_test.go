@@ -0,0 +1,191 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"encoding/pem"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const sampleIndex = `apiVersion: v1
+entries:
+  sample:
+  - name: sample
+    version: 0.1.0
+    created: "2020-10-01T00:00:00Z"
+    digest: 0f8b2d
+    urls:
+    - charts/sample-0.1.0.tgz
+`
+
+func TestHelm_GetValuesWithBasicAuthAndCABundle(t *testing.T) {
+	server := newChartServer(t, func(r *http.Request) bool {
+		username, password, ok := r.BasicAuth()
+		return ok && username == "admin" && password == "secret"
+	})
+	defer server.Close()
+
+	repo := model.Repo{
+		Name:     "private",
+		URL:      server.URL,
+		Username: "admin",
+		Password: "secret",
+		CABundle: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+	}
+
+	h := helm.NewHelmClient(nil, 0)
+	err, values := h.GetValues(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), values["replicaCount"])
+}
+
+func TestHelm_GetManifestWithBearerToken(t *testing.T) {
+	server := newChartServer(t, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer token"
+	})
+	defer server.Close()
+
+	repo := model.Repo{
+		Name:                  "private",
+		URL:                   server.URL,
+		Token:                 "token",
+		InsecureSkipTLSVerify: true,
+	}
+
+	h := helm.NewHelmClient(nil, 0)
+	templates, err := h.GetManifest(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	assert.ElementsMatch(t, []string{"templates/_helpers.tpl", "templates/deployment.yaml", "templates/service.yaml"}, names)
+}
+
+func TestHelm_GetIndexUnauthorized(t *testing.T) {
+	server := newChartServer(t, func(r *http.Request) bool {
+		return false
+	})
+	defer server.Close()
+
+	repo := model.Repo{Name: "private", URL: server.URL, InsecureSkipTLSVerify: true}
+
+	h := helm.NewHelmClient(nil, 0)
+	_, err := h.GetIndex(repo)
+	assert.Error(t, err)
+}
+
+func TestHelm_GetIndexUnknownCA(t *testing.T) {
+	server := newChartServer(t, func(r *http.Request) bool {
+		return true
+	})
+	defer server.Close()
+
+	repo := model.Repo{Name: "private", URL: server.URL}
+
+	h := helm.NewHelmClient(nil, 0)
+	_, err := h.GetIndex(repo)
+	assert.Error(t, err)
+}
+
+func TestHelm_LoadChartReusesIndex(t *testing.T) {
+	indexDownloads := 0
+	server := newChartServer(t, func(r *http.Request) bool {
+		if r.URL.Path == "/index.yaml" {
+			indexDownloads++
+		}
+		return true
+	})
+	defer server.Close()
+
+	repo := model.Repo{Name: "private", URL: server.URL, InsecureSkipTLSVerify: true}
+
+	h := helm.NewHelmClient(nil, time.Hour)
+	err, _ := h.GetValues(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+	_, err = h.GetManifest(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+	_, err = h.GetChartMetadata(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, indexDownloads)
+
+	_, err = h.GetIndex(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, indexDownloads)
+}
+
+func TestHelm_ReusesRepoConnections(t *testing.T) {
+	connections := map[string]bool{}
+	server := newChartServer(t, func(r *http.Request) bool {
+		connections[r.RemoteAddr] = true
+		return r.Header.Get("Authorization") != "Bearer revoked"
+	})
+	defer server.Close()
+
+	repo := model.Repo{Name: "private", URL: server.URL, InsecureSkipTLSVerify: true}
+
+	h := helm.NewHelmClient(nil, 0)
+	for i := 0; i < 3; i++ {
+		_, err := h.GetIndex(repo)
+		assert.NoError(t, err)
+		err, _ = h.GetValues(repo, "sample", "0.1.0")
+		assert.NoError(t, err)
+	}
+	assert.Len(t, connections, 1)
+
+	repo.Token = "revoked"
+	_, err := h.GetIndex(repo)
+	assert.Error(t, err)
+	assert.Len(t, connections, 2)
+}
+
+// newChartServer serves the testdata sample chart over TLS, rejecting requests that are not authorized.
+func newChartServer(t *testing.T, authorized func(r *http.Request) bool) *httptest.Server {
+	return serveChart(t, "testdata/sample", sampleIndex, authorized)
+}
+
+// serveChart serves the index and the archive of the chart at path, the index links the archive as
+// charts/{name}-{version}.tgz.
+func serveChart(t *testing.T, path, index string, authorized func(r *http.Request) bool) *httptest.Server {
+	c, err := loader.Load(path)
+	assert.NoError(t, err)
+	archive := packageChart(t, path)
+
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/index.yaml":
+			w.Write([]byte(index))
+		case fmt.Sprintf("/charts/%s-%s.tgz", c.Name(), c.Metadata.Version):
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func packageChart(t *testing.T, path string) []byte {
+	c, err := loader.Load(path)
+	assert.NoError(t, err)
+
+	archivePath, err := chartutil.Save(c, t.TempDir())
+	assert.NoError(t, err)
+
+	archive, err := ioutil.ReadFile(archivePath)
+	assert.NoError(t, err)
+
+	return archive
+}
@@ -0,0 +1,77 @@
+package helm
+
+import (
+	"chart-viewer/pkg/model"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// GetChartMetadata returns the Chart.yaml of the chart version completed by its repo index entry.
+func (h helm) GetChartMetadata(repo model.Repo, chartName, chartVersion string) (model.ChartMetadata, error) {
+	chartRequested, entry, err := h.loadChartEntry(repo, chartName, chartVersion)
+	if err != nil {
+		return model.ChartMetadata{}, err
+	}
+
+	return chartMetadata(chartRequested.Metadata, entry), nil
+}
+
+func chartMetadata(metadata *chart.Metadata, entry model.ChartResponse) model.ChartMetadata {
+	chartType := metadata.Type
+	if chartType == "" {
+		chartType = "application"
+	}
+
+	result := model.ChartMetadata{
+		Name:        metadata.Name,
+		Version:     metadata.Version,
+		APIVersion:  metadata.APIVersion,
+		Type:        chartType,
+		Description: metadata.Description,
+		AppVersion:  metadata.AppVersion,
+		KubeVersion: metadata.KubeVersion,
+		Home:        metadata.Home,
+		Icon:        metadata.Icon,
+		Keywords:    metadata.Keywords,
+		Sources:     metadata.Sources,
+		Deprecated:  metadata.Deprecated || entry.Deprecated,
+		Annotations: metadata.Annotations,
+		Created:     entry.Created,
+		Digest:      entry.Digest,
+		URLs:        entry.URLs,
+	}
+
+	if result.Description == "" {
+		result.Description = entry.Description
+	}
+	if result.AppVersion == "" {
+		result.AppVersion = entry.AppVersion
+	}
+
+	for _, maintainer := range metadata.Maintainers {
+		if maintainer == nil {
+			continue
+		}
+		result.Maintainers = append(result.Maintainers, model.ChartMaintainer{
+			Name:  maintainer.Name,
+			Email: maintainer.Email,
+			URL:   maintainer.URL,
+		})
+	}
+
+	for _, dependency := range metadata.Dependencies {
+		if dependency == nil {
+			continue
+		}
+		result.Dependencies = append(result.Dependencies, model.ChartDependency{
+			Name:       dependency.Name,
+			Version:    dependency.Version,
+			Repository: dependency.Repository,
+			Condition:  dependency.Condition,
+			Tags:       dependency.Tags,
+			Alias:      dependency.Alias,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,68 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const documentedIndex = `apiVersion: v1
+entries:
+  documented:
+  - name: documented
+    version: 0.1.0
+    created: "2020-10-01T00:00:00Z"
+    digest: 5d41402a
+    urls:
+    - charts/documented-0.1.0.tgz
+`
+
+func TestHelm_GetChartMetadata(t *testing.T) {
+	server := serveChart(t, "testdata/documented", documentedIndex, func(r *http.Request) bool { return true })
+	defer server.Close()
+
+	h := helm.NewHelmClient(nil, 0)
+	repo := model.Repo{Name: "remote", URL: server.URL, InsecureSkipTLSVerify: true}
+
+	metadata, err := h.GetChartMetadata(repo, "documented", "0.1.0")
+	assert.NoError(t, err)
+
+	expected := model.ChartMetadata{
+		Name:        "documented",
+		Version:     "0.1.0",
+		APIVersion:  "v2",
+		Type:        "application",
+		Description: "A chart filling every Chart.yaml field read by the metadata tests",
+		AppVersion:  "1.16.0",
+		KubeVersion: ">=1.16.0-0",
+		Home:        "https://example.com/documented",
+		Icon:        "https://example.com/documented.png",
+		Keywords:    []string{"web", "nginx"},
+		Sources:     []string{"https://github.com/example/documented"},
+		Maintainers: []model.ChartMaintainer{{Name: "documented-team", Email: "documented@example.com"}},
+		Annotations: map[string]string{"category": "WebServer"},
+		Created:     "2020-10-01T00:00:00Z",
+		Digest:      "5d41402a",
+		URLs:        []string{"charts/documented-0.1.0.tgz"},
+	}
+	assert.Equal(t, expected, metadata)
+}
+
+func TestHelm_GetChartMetadataWithDependencies(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/umbrella"))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	metadata, err := h.GetChartMetadata(uploadsRepo, uploaded.ID, "0.1.0")
+	assert.NoError(t, err)
+
+	assert.True(t, metadata.Deprecated)
+	assert.Equal(t, uploaded.ID, metadata.Digest)
+	assert.Equal(t, []model.ChartDependency{
+		{Name: "sample", Version: "0.1.0", Repository: "file://../sample", Condition: "sample.enabled", Tags: []string{"frontend"}, Alias: "web"},
+	}, metadata.Dependencies)
+}
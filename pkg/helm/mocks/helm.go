@@ -13,36 +13,108 @@ type Helm struct {
 	mock.Mock
 }
 
-// GetManifest provides a mock function with given fields: chartUrl, chartName, chartVersion
-func (_m *Helm) GetManifest(chartUrl string, chartName string, chartVersion string) []model.Template {
-	ret := _m.Called(chartUrl, chartName, chartVersion)
+// GetChartMetadata provides a mock function with given fields: repo, chartName, chartVersion
+func (_m *Helm) GetChartMetadata(repo model.Repo, chartName string, chartVersion string) (model.ChartMetadata, error) {
+	ret := _m.Called(repo, chartName, chartVersion)
+
+	var r0 model.ChartMetadata
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string) model.ChartMetadata); ok {
+		r0 = rf(repo, chartName, chartVersion)
+	} else {
+		r0 = ret.Get(0).(model.ChartMetadata)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string) error); ok {
+		r1 = rf(repo, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDependencies provides a mock function with given fields: repo, chartName, chartVersion
+func (_m *Helm) GetDependencies(repo model.Repo, chartName string, chartVersion string) ([]model.Subchart, error) {
+	ret := _m.Called(repo, chartName, chartVersion)
+
+	var r0 []model.Subchart
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string) []model.Subchart); ok {
+		r0 = rf(repo, chartName, chartVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Subchart)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string) error); ok {
+		r1 = rf(repo, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIndex provides a mock function with given fields: repo
+func (_m *Helm) GetIndex(repo model.Repo) (model.RepoDetailResponse, error) {
+	ret := _m.Called(repo)
+
+	var r0 model.RepoDetailResponse
+	if rf, ok := ret.Get(0).(func(model.Repo) model.RepoDetailResponse); ok {
+		r0 = rf(repo)
+	} else {
+		r0 = ret.Get(0).(model.RepoDetailResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo) error); ok {
+		r1 = rf(repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetManifest provides a mock function with given fields: repo, chartName, chartVersion
+func (_m *Helm) GetManifest(repo model.Repo, chartName string, chartVersion string) ([]model.Template, error) {
+	ret := _m.Called(repo, chartName, chartVersion)
 
 	var r0 []model.Template
-	if rf, ok := ret.Get(0).(func(string, string, string) []model.Template); ok {
-		r0 = rf(chartUrl, chartName, chartVersion)
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string) []model.Template); ok {
+		r0 = rf(repo, chartName, chartVersion)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Template)
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string) error); ok {
+		r1 = rf(repo, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetValues provides a mock function with given fields: chartUrl, chartName, chartVersion
-func (_m *Helm) GetValues(chartUrl string, chartName string, chartVersion string) (error, map[string]interface{}) {
-	ret := _m.Called(chartUrl, chartName, chartVersion)
+// GetValues provides a mock function with given fields: repo, chartName, chartVersion
+func (_m *Helm) GetValues(repo model.Repo, chartName string, chartVersion string) (error, map[string]interface{}) {
+	ret := _m.Called(repo, chartName, chartVersion)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(chartUrl, chartName, chartVersion)
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string) error); ok {
+		r0 = rf(repo, chartName, chartVersion)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 map[string]interface{}
-	if rf, ok := ret.Get(1).(func(string, string, string) map[string]interface{}); ok {
-		r1 = rf(chartUrl, chartName, chartVersion)
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string) map[string]interface{}); ok {
+		r1 = rf(repo, chartName, chartVersion)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(map[string]interface{})
@@ -52,20 +124,43 @@ func (_m *Helm) GetValues(chartUrl string, chartName string, chartVersion string
 	return r0, r1
 }
 
-// RenderManifest provides a mock function with given fields: chartUrl, chartName, chartVersion, files
-func (_m *Helm) RenderManifest(chartUrl string, chartName string, chartVersion string, files []string) (error, []model.Manifest) {
-	ret := _m.Called(chartUrl, chartName, chartVersion, files)
+// GetValuesSchema provides a mock function with given fields: repo, chartName, chartVersion
+func (_m *Helm) GetValuesSchema(repo model.Repo, chartName string, chartVersion string) ([]byte, error) {
+	ret := _m.Called(repo, chartName, chartVersion)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string) []byte); ok {
+		r0 = rf(repo, chartName, chartVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string) error); ok {
+		r1 = rf(repo, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenderManifest provides a mock function with given fields: repo, chartName, chartVersion, renderOptions
+func (_m *Helm) RenderManifest(repo model.Repo, chartName string, chartVersion string, renderOptions model.RenderOptions) (error, []model.Manifest) {
+	ret := _m.Called(repo, chartName, chartVersion, renderOptions)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, []string) error); ok {
-		r0 = rf(chartUrl, chartName, chartVersion, files)
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string, model.RenderOptions) error); ok {
+		r0 = rf(repo, chartName, chartVersion, renderOptions)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 []model.Manifest
-	if rf, ok := ret.Get(1).(func(string, string, string, []string) []model.Manifest); ok {
-		r1 = rf(chartUrl, chartName, chartVersion, files)
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string, model.RenderOptions) []model.Manifest); ok {
+		r1 = rf(repo, chartName, chartVersion, renderOptions)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).([]model.Manifest)
@@ -74,3 +169,47 @@ func (_m *Helm) RenderManifest(chartUrl string, chartName string, chartVersion s
 
 	return r0, r1
 }
+
+// UploadChart provides a mock function with given fields: archive
+func (_m *Helm) UploadChart(archive []byte) (model.UploadedChart, error) {
+	ret := _m.Called(archive)
+
+	var r0 model.UploadedChart
+	if rf, ok := ret.Get(0).(func([]byte) model.UploadedChart); ok {
+		r0 = rf(archive)
+	} else {
+		r0 = ret.Get(0).(model.UploadedChart)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(archive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateValues provides a mock function with given fields: repo, chartName, chartVersion, valuesOptions
+func (_m *Helm) ValidateValues(repo model.Repo, chartName string, chartVersion string, valuesOptions model.ValuesOptions) ([]model.SchemaError, error) {
+	ret := _m.Called(repo, chartName, chartVersion, valuesOptions)
+
+	var r0 []model.SchemaError
+	if rf, ok := ret.Get(0).(func(model.Repo, string, string, model.ValuesOptions) []model.SchemaError); ok {
+		r0 = rf(repo, chartName, chartVersion, valuesOptions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.SchemaError)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(model.Repo, string, string, model.ValuesOptions) error); ok {
+		r1 = rf(repo, chartName, chartVersion, valuesOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,378 @@
+package helm
+
+import (
+	"chart-viewer/pkg/model"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociCatalogPageSize   = 1000
+)
+
+// Helm 3.7 onwards pushes charts with the first layer media type, older releases used the second one.
+var ociChartLayerMediaTypes = []string{
+	"application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+	"application/tar+gzip",
+}
+
+var (
+	linkNextRegex       = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+	challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+type ociManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+// ociClient talks to the distribution API of the registry behind an oci:// repo,
+// charts are stored as repositories below the repo path and their versions as tags.
+type ociClient struct {
+	repo     model.Repo
+	client   *http.Client
+	registry string
+	path     string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (h helm) newOCIClient(repo model.Repo) (*ociClient, error) {
+	u, err := url.Parse(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("oci repo %s url %q has no registry host", repo.Name, repo.URL)
+	}
+
+	client, err := h.clients.get(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "https"
+	if repo.PlainHTTP {
+		scheme = "http"
+	}
+
+	return &ociClient{
+		repo:     repo,
+		client:   client,
+		registry: fmt.Sprintf("%s://%s", scheme, u.Host),
+		path:     strings.Trim(u.Path, "/"),
+		tokens:   map[string]string{},
+	}, nil
+}
+
+// getIndex builds the same index an HTTP repo serves from the charts and tags of the registry.
+func (c *ociClient) getIndex() (model.RepoDetailResponse, error) {
+	index := model.RepoDetailResponse{
+		ApiVersion: "v1",
+		Entries:    map[string][]model.ChartResponse{},
+	}
+
+	charts, err := c.listCharts()
+	if err != nil {
+		return index, err
+	}
+
+	for _, chartName := range charts {
+		tags, err := c.listTags(chartName)
+		if err != nil {
+			return index, err
+		}
+
+		for _, tag := range tags {
+			index.Entries[chartName] = append(index.Entries[chartName], model.ChartResponse{
+				Name:    chartName,
+				Version: tagToVersion(tag),
+			})
+		}
+	}
+
+	return index, nil
+}
+
+func (c *ociClient) listCharts() ([]string, error) {
+	if len(c.repo.Charts) > 0 {
+		return c.repo.Charts, nil
+	}
+
+	var charts []string
+	prefix := ""
+	if c.path != "" {
+		prefix = c.path + "/"
+	}
+
+	next := fmt.Sprintf("%s/v2/_catalog?n=%d", c.registry, ociCatalogPageSize)
+	for next != "" {
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+
+		response, err := c.get(next, "application/json", "registry:catalog:*")
+		if err != nil {
+			return nil, fmt.Errorf("listing charts of oci repo %s, set its charts if the registry has no catalog: %w", c.repo.Name, err)
+		}
+
+		next, err = decodePage(response, &catalog)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, repository := range catalog.Repositories {
+			name := strings.TrimPrefix(repository, prefix)
+			if strings.HasPrefix(repository, prefix) && !strings.Contains(name, "/") {
+				charts = append(charts, name)
+			}
+		}
+	}
+
+	sort.Strings(charts)
+	return charts, nil
+}
+
+func (c *ociClient) listTags(chartName string) ([]string, error) {
+	var tags []string
+
+	repository := c.repository(chartName)
+	next := fmt.Sprintf("%s/v2/%s/tags/list", c.registry, repository)
+	for next != "" {
+		var tagList struct {
+			Tags []string `json:"tags"`
+		}
+
+		response, err := c.get(next, "application/json", pullScope(repository))
+		if err != nil {
+			return nil, err
+		}
+
+		next, err = decodePage(response, &tagList)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tagList.Tags...)
+	}
+
+	return tags, nil
+}
+
+// pullChart returns the chart archive stored as layer of the tagged manifest.
+func (c *ociClient) pullChart(chartName, chartVersion string) ([]byte, error) {
+	repository := c.repository(chartName)
+	manifestUrl := fmt.Sprintf("%s/v2/%s/manifests/%s", c.registry, repository, versionToTag(chartVersion))
+
+	response, err := c.get(manifestUrl, ociManifestMediaType, pullScope(repository))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest ociManifest
+	_, err = decodePage(response, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := ""
+	for _, mediaType := range ociChartLayerMediaTypes {
+		for _, layer := range manifest.Layers {
+			if digest == "" && layer.MediaType == mediaType {
+				digest = layer.Digest
+			}
+		}
+	}
+
+	if digest == "" {
+		return nil, fmt.Errorf("%s:%s of oci repo %s is not a helm chart", chartName, chartVersion, c.repo.Name)
+	}
+
+	blobUrl := fmt.Sprintf("%s/v2/%s/blobs/%s", c.registry, repository, digest)
+	response, err = c.get(blobUrl, "", pullScope(repository))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	return ioutil.ReadAll(response.Body)
+}
+
+func (c *ociClient) repository(chartName string) string {
+	if c.path == "" {
+		return chartName
+	}
+
+	return c.path + "/" + chartName
+}
+
+// get sends the request and, when the registry answers with a bearer challenge,
+// fetches a token for the scope and retries with it.
+func (c *ociClient) get(requestUrl, accept, scope string) (*http.Response, error) {
+	response, err := c.do(requestUrl, accept, c.token(scope))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, fmt.Errorf("fetching %s returned 401 Unauthorized", requestUrl)
+		}
+
+		token, err := c.fetchToken(challenge, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err = c.do(requestUrl, accept, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("fetching %s returned %s", requestUrl, response.Status)
+	}
+
+	return response, nil
+}
+
+func (c *ociClient) do(requestUrl, accept, token string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	log.Printf("out going call: %s\n", requestUrl)
+	return c.client.Do(request)
+}
+
+func (c *ociClient) token(scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tokens[scope]
+}
+
+// fetchToken implements the token flow of the distribution spec, the repo username and password
+// are sent as basic auth to the token realm so anonymous and authenticated pulls both work.
+func (c *ociClient) fetchToken(challenge, scope string) (string, error) {
+	params := map[string]string{}
+	for _, match := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("registry of oci repo %s sent an invalid auth challenge %q", c.repo.Name, challenge)
+	}
+
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if c.repo.Username != "" || c.repo.Password != "" {
+		request.SetBasicAuth(c.repo.Username, c.repo.Password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching registry token of oci repo %s returned %s", c.repo.Name, response.Status)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&tokenResponse)
+	if err != nil {
+		return "", err
+	}
+
+	token := tokenResponse.Token
+	if token == "" {
+		token = tokenResponse.AccessToken
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = token
+	c.mu.Unlock()
+
+	return token, nil
+}
+
+// decodePage decodes the JSON body and returns the absolute URL of the next page, if any.
+func decodePage(response *http.Response, target interface{}) (string, error) {
+	defer response.Body.Close()
+
+	err := json.NewDecoder(response.Body).Decode(target)
+	if err != nil {
+		return "", err
+	}
+
+	match := linkNextRegex.FindStringSubmatch(response.Header.Get("Link"))
+	if match == nil {
+		return "", nil
+	}
+
+	next, err := response.Request.URL.Parse(match[1])
+	if err != nil {
+		return "", err
+	}
+
+	return next.String(), nil
+}
+
+func pullScope(repository string) string {
+	return fmt.Sprintf("repository:%s:pull", repository)
+}
+
+// OCI tags cannot contain '+', helm stores the semver build metadata separator as '_'.
+func versionToTag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+func tagToVersion(tag string) string {
+	return strings.ReplaceAll(tag, "_", "+")
+}
@@ -0,0 +1,127 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRegistryServer is a minimal OCI distribution registry holding the sample chart
+// as myorg/charts/sample:0.1.0 and 0.2.0+build, protected by the bearer token flow.
+func newRegistryServer(t *testing.T) *httptest.Server {
+	archive := packageChart(t, "testdata/sample")
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(archive))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			username, password, _ := r.BasicAuth()
+			if username != "reader" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "registry-token:" + r.URL.Query().Get("scope")})
+			return
+		}
+
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer registry-token:") {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/_catalog":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/_catalog?last=myorg%2Fcharts%2Fsample&n=1000>; rel="next"`)
+				json.NewEncoder(w).Encode(map[string][]string{"repositories": {"myorg/charts/sample"}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"myorg/images/nginx", "myorg/charts/nested/chart"}})
+		case "/v2/myorg/charts/sample/tags/list":
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "myorg/charts/sample", "tags": []string{"0.1.0", "0.2.0_build"}})
+		case "/v2/myorg/charts/sample/manifests/0.1.0":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"schemaVersion": 2,
+				"layers": []map[string]string{
+					{"mediaType": "application/vnd.cncf.helm.chart.provenance.v1.prov", "digest": "sha256:prov"},
+					{"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip", "digest": digest},
+				},
+			})
+		case "/v2/myorg/charts/sample/blobs/" + digest:
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func TestHelm_GetIndexFromOCIRegistry(t *testing.T) {
+	server := newRegistryServer(t)
+	defer server.Close()
+
+	repo := model.Repo{
+		Name:      "registry",
+		URL:       "oci://" + strings.TrimPrefix(server.URL, "http://") + "/myorg/charts",
+		Username:  "reader",
+		Password:  "secret",
+		PlainHTTP: true,
+	}
+
+	h := helm.NewHelmClient(nil, 0)
+	index, err := h.GetIndex(repo)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string][]model.ChartResponse{
+		"sample": {
+			{Name: "sample", Version: "0.1.0"},
+			{Name: "sample", Version: "0.2.0+build"},
+		},
+	}, index.Entries)
+}
+
+func TestHelm_GetValuesFromOCIRegistry(t *testing.T) {
+	server := newRegistryServer(t)
+	defer server.Close()
+
+	repo := model.Repo{
+		Name:      "registry",
+		Type:      model.RepoTypeOCI,
+		URL:       "oci://" + strings.TrimPrefix(server.URL, "http://") + "/myorg/charts",
+		Username:  "reader",
+		Password:  "secret",
+		Charts:    []string{"sample"},
+		PlainHTTP: true,
+	}
+
+	h := helm.NewHelmClient(nil, 0)
+	err, values := h.GetValues(repo, "sample", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), values["replicaCount"])
+}
+
+func TestHelm_GetValuesFromOCIRegistryWrongCredentials(t *testing.T) {
+	server := newRegistryServer(t)
+	defer server.Close()
+
+	repo := model.Repo{
+		Name:      "registry",
+		URL:       "oci://" + strings.TrimPrefix(server.URL, "http://") + "/myorg/charts",
+		Username:  "reader",
+		Password:  "wrong",
+		PlainHTTP: true,
+	}
+
+	h := helm.NewHelmClient(nil, 0)
+	err, _ := h.GetValues(repo, "sample", "0.1.0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package helm
+
+import (
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+var ErrInvalidRenderOptions = errors.New("invalid render options")
+
+// newInstall prepares a dry run install for one render. The capabilities are given to the action configuration
+// instead of using client only mode, which would render with the shared chartutil.DefaultCapabilities.
+func newInstall(releaseName string, options model.RenderOptions) (*action.Install, error) {
+	capabilities, err := newCapabilities(options.KubeVersion, options.APIVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = settings.Namespace()
+	}
+
+	releases := driver.NewMemory()
+	releases.SetNamespace(namespace)
+
+	client := action.NewInstall(&action.Configuration{
+		Releases:     storage.Init(releases),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: capabilities,
+		Log:          debug,
+	})
+	client.DryRun = true
+	client.UseReleaseName = true
+	client.ReleaseName = releaseName
+	client.Namespace = namespace
+	client.IncludeCRDs = options.IncludeCRDs
+	client.DisableHooks = options.DisableHooks
+	client.IsUpgrade = options.IsUpgrade
+
+	if options.ReleaseName != "" {
+		client.ReleaseName = options.ReleaseName
+	}
+
+	return client, nil
+}
+
+// newCapabilities builds the capabilities of the target cluster on top of the defaults of helm template.
+func newCapabilities(kubeVersion string, apiVersions []string) (*chartutil.Capabilities, error) {
+	capabilities := &chartutil.Capabilities{
+		KubeVersion: chartutil.DefaultCapabilities.KubeVersion,
+		APIVersions: append(chartutil.VersionSet{}, chartutil.DefaultVersionSet...),
+	}
+
+	if kubeVersion != "" {
+		version, err := kubeversion.Parse(kubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRenderOptions, err)
+		}
+		capabilities.KubeVersion = chartutil.KubeVersion{
+			Version: version.String(),
+			Major:   strconv.Itoa(version.Major),
+			Minor:   strconv.Itoa(version.Minor),
+		}
+	}
+
+	for _, apiVersion := range apiVersions {
+		if strings.TrimSpace(apiVersion) == "" {
+			return nil, fmt.Errorf("%w: empty api version", ErrInvalidRenderOptions)
+		}
+		capabilities.APIVersions = append(capabilities.APIVersions, apiVersion)
+	}
+
+	return capabilities, nil
+}
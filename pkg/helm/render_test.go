@@ -0,0 +1,127 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func renderChart(t *testing.T, path string, renderOptions model.RenderOptions) map[string]string {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChart(t, path))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	err, manifests := h.RenderManifest(uploadsRepo, uploaded.ID, "0.1.0", renderOptions)
+	assert.NoError(t, err)
+
+	rendered := map[string]string{}
+	for _, manifest := range manifests {
+		rendered[manifest.Name] = manifest.Content
+	}
+
+	return rendered
+}
+
+func TestHelm_RenderManifestWithValuesLayers(t *testing.T) {
+	rendered := renderChart(t, "testdata/sample", model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{
+			Values: model.ValuesDocuments{
+				"replicaCount: 2\nimage:\n  tag: 1.17.0",
+				"replicaCount: 3",
+			},
+		},
+	})
+
+	assert.Contains(t, rendered["deployment.yaml"], "replicas: 3")
+	assert.Contains(t, rendered["deployment.yaml"], "nginx:1.17.0")
+}
+
+func TestHelm_RenderManifestWithSetOverrides(t *testing.T) {
+	rendered := renderChart(t, "testdata/sample", model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{
+			Values:    model.ValuesDocuments{"replicaCount: 2"},
+			Set:       []string{"replicaCount=4", "service.port=8080"},
+			SetString: []string{"image.tag=1.18"},
+			SetFile:   map[string]string{"service.type": "NodePort"},
+		},
+	})
+
+	assert.Contains(t, rendered["deployment.yaml"], "replicas: 4")
+	assert.Contains(t, rendered["deployment.yaml"], "nginx:1.18")
+	assert.Contains(t, rendered["service.yaml"], "port: 8080")
+	assert.Contains(t, rendered["service.yaml"], "type: NodePort")
+}
+
+func TestHelm_RenderManifestDefaultOptions(t *testing.T) {
+	rendered := renderChart(t, "testdata/release", model.RenderOptions{})
+
+	assert.Contains(t, rendered["configmap.yaml"], "name: release\n")
+	assert.Contains(t, rendered["configmap.yaml"], "namespace: default")
+	assert.Contains(t, rendered["configmap.yaml"], `kubeVersion: "v1.16.0"`)
+	assert.Contains(t, rendered["configmap.yaml"], `isUpgrade: "false"`)
+	assert.Contains(t, rendered["configmap.yaml"], `deployment: "served"`)
+	assert.Contains(t, rendered["hook.yaml"], "name: release-migrate")
+	assert.NotContains(t, rendered, "servicemonitor.yaml")
+	assert.NotContains(t, rendered, "crds/widget.yaml")
+}
+
+func TestHelm_RenderManifestWithRenderOptions(t *testing.T) {
+	rendered := renderChart(t, "testdata/release", model.RenderOptions{
+		ReleaseName:  "my-release",
+		Namespace:    "apps",
+		KubeVersion:  "1.21.3",
+		APIVersions:  []string{"monitoring.coreos.com/v1"},
+		IncludeCRDs:  true,
+		DisableHooks: true,
+		IsUpgrade:    true,
+	})
+
+	assert.Contains(t, rendered["configmap.yaml"], "name: my-release\n")
+	assert.Contains(t, rendered["configmap.yaml"], "namespace: apps")
+	assert.Contains(t, rendered["configmap.yaml"], `kubeVersion: "v1.21.3"`)
+	assert.Contains(t, rendered["configmap.yaml"], `isUpgrade: "true"`)
+	assert.Contains(t, rendered["servicemonitor.yaml"], "kind: ServiceMonitor")
+	assert.Contains(t, rendered["crds/widget.yaml"], "kind: CustomResourceDefinition")
+	assert.NotContains(t, rendered, "hook.yaml")
+}
+
+func TestHelm_RenderManifestInvalidKubeVersion(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/release"))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	err, _ = h.RenderManifest(uploadsRepo, uploaded.ID, "0.1.0", model.RenderOptions{KubeVersion: "latest"})
+	assert.True(t, errors.Is(err, helm.ErrInvalidRenderOptions))
+}
+
+func TestHelm_RenderManifestSetFileKeyReadingPath(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, ioutil.WriteFile(secret, []byte("s3cr3t"), 0600))
+
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/sample"))
+	assert.NoError(t, err)
+
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	for _, key := range []string{"service.type=" + secret + ",x", "service.type=" + secret, "service.type,image.tag"} {
+		renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{SetFile: map[string]string{key: "NodePort"}}}
+		err, manifests := h.RenderManifest(uploadsRepo, uploaded.ID, "0.1.0", renderOptions)
+		assert.True(t, errors.Is(err, helm.ErrInvalidRenderOptions), key)
+		assert.Empty(t, manifests, key)
+	}
+}
+
+func TestHelm_RenderManifestSetFileContentIsNotParsed(t *testing.T) {
+	rendered := renderChart(t, "testdata/sample", model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{SetFile: map[string]string{"service.type": "Node,Port=x"}},
+	})
+
+	assert.Contains(t, rendered["service.yaml"], "type: Node,Port=x")
+}
@@ -0,0 +1,126 @@
+package helm
+
+import (
+	"chart-viewer/pkg/jsonpath"
+	"chart-viewer/pkg/model"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// InvalidValuesError is returned when rendering with values that do not match the values.schema.json of the chart.
+type InvalidValuesError struct {
+	Errors []model.SchemaError
+}
+
+func (e *InvalidValuesError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, schemaError := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", schemaError.Path, schemaError.Message))
+	}
+
+	return "values do not match the chart schema: " + strings.Join(messages, "; ")
+}
+
+// GetValuesSchema returns the values.schema.json of the chart, nil when the chart has none.
+func (h helm) GetValuesSchema(repo model.Repo, chartName, chartVersion string) ([]byte, error) {
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return chartRequested.Schema, nil
+}
+
+// ValidateValues merges the values like RenderManifest does and checks them against the schemas of the chart and its subcharts.
+func (h helm) ValidateValues(repo model.Repo, chartName, chartVersion string, valuesOptions model.ValuesOptions) ([]model.SchemaError, error) {
+	chartRequested, err := h.loadChart(repo, chartName, chartVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, err := mergeValues(valuesOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateValues(chartRequested, vals)
+}
+
+func validateValues(c *chart.Chart, vals map[string]interface{}) ([]model.SchemaError, error) {
+	err := chartutil.ProcessDependencies(c, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	coalesced, err := chartutil.CoalesceValues(c, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateChartValues(c, coalesced, jsonpath.Root)
+}
+
+func validateChartValues(c *chart.Chart, values map[string]interface{}, path string) ([]model.SchemaError, error) {
+	schemaErrors := make([]model.SchemaError, 0)
+
+	if c.Schema != nil {
+		valuesJSON, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+		if string(valuesJSON) == "null" {
+			valuesJSON = []byte("{}")
+		}
+
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(c.Schema), gojsonschema.NewBytesLoader(valuesJSON))
+		if err != nil {
+			return nil, fmt.Errorf("%w: values.schema.json of %s: %s", ErrInvalidChart, c.Name(), err)
+		}
+
+		for _, resultError := range result.Errors() {
+			schemaErrors = append(schemaErrors, model.SchemaError{
+				Path:    schemaErrorPath(path, resultError),
+				Type:    resultError.Type(),
+				Message: resultError.Description(),
+			})
+		}
+	}
+
+	for _, subchart := range c.Dependencies() {
+		subchartValues, _ := values[subchart.Name()].(map[string]interface{})
+		subchartErrors, err := validateChartValues(subchart, subchartValues, jsonpath.Key(path, subchart.Name()))
+		if err != nil {
+			return nil, err
+		}
+		schemaErrors = append(schemaErrors, subchartErrors...)
+	}
+
+	return schemaErrors, nil
+}
+
+// schemaErrorPath turns the field of a schema error, like containers.0.name, into a JSON path below path.
+// Required errors point to the missing property instead of the object missing it.
+func schemaErrorPath(path string, resultError gojsonschema.ResultError) string {
+	field := resultError.Field()
+	if field != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+		for _, key := range strings.Split(field, ".") {
+			if _, err := strconv.Atoi(key); err == nil {
+				path = fmt.Sprintf("%s[%s]", path, key)
+				continue
+			}
+			path = jsonpath.Key(path, key)
+		}
+	}
+
+	if property, ok := resultError.Details()["property"].(string); ok && resultError.Type() == "required" {
+		path = jsonpath.Key(path, property)
+	}
+
+	return path
+}
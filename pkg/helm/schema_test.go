@@ -0,0 +1,67 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHelm_GetValuesSchema(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+
+	withSchema, err := h.UploadChart(packageChart(t, "testdata/schema"))
+	assert.NoError(t, err)
+	schema, err := h.GetValuesSchema(uploadsRepo, withSchema.ID, "0.1.0")
+	assert.NoError(t, err)
+	assert.Contains(t, string(schema), `"required": ["image", "replicaCount"]`)
+
+	withoutSchema, err := h.UploadChart(packageChart(t, "testdata/sample"))
+	assert.NoError(t, err)
+	schema, err = h.GetValuesSchema(uploadsRepo, withoutSchema.ID, "0.1.0")
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+}
+
+func TestHelm_ValidateValues(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/schema"))
+	assert.NoError(t, err)
+
+	schemaErrors, err := h.ValidateValues(uploadsRepo, uploaded.ID, "0.1.0", model.ValuesOptions{
+		Values: model.ValuesDocuments{"replicaCount: 2\nimage:\n  tag: \"1.17.0\""},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, schemaErrors)
+
+	schemaErrors, err = h.ValidateValues(uploadsRepo, uploaded.ID, "0.1.0", model.ValuesOptions{
+		Values: model.ValuesDocuments{"replicaCount: two\nimage:\n  repository: null\nports:\n  - name: http\n    port: eighty"},
+		Set:    []string{"image.tag=true"},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []model.SchemaError{
+		{Path: "$.replicaCount", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"},
+		{Path: "$.image.repository", Type: "required", Message: "repository is required"},
+		{Path: "$.image.tag", Type: "invalid_type", Message: "Invalid type. Expected: string, given: boolean"},
+		{Path: "$.ports[0].port", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"},
+	}, schemaErrors)
+}
+
+func TestHelm_RenderManifestWithInvalidValues(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/schema"))
+	assert.NoError(t, err)
+
+	err, _ = h.RenderManifest(uploadsRepo, uploaded.ID, "0.1.0", model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{Set: []string{"replicaCount=two"}},
+	})
+
+	var invalidValues *helm.InvalidValuesError
+	assert.True(t, errors.As(err, &invalidValues))
+	assert.Equal(t, []model.SchemaError{{Path: "$.replicaCount", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"}}, invalidValues.Errors)
+}
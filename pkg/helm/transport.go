@@ -0,0 +1,161 @@
+package helm
+
+import (
+	"chart-viewer/pkg/model"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const httpTimeout = time.Minute
+
+// clientCache keeps one client per repo, so the connections to a repo are reused by the following requests. A repo
+// whose url, TLS settings or credentials changed gets a new client, the idle connections of the previous one are
+// closed.
+type clientCache struct {
+	mutex   sync.Mutex
+	entries map[string]cachedClient
+}
+
+type cachedClient struct {
+	settings clientSettings
+	client   *http.Client
+}
+
+// clientSettings are the fields of a repo a client is built from.
+type clientSettings struct {
+	url                   string
+	username              string
+	password              string
+	token                 string
+	caBundle              string
+	certData              string
+	keyData               string
+	insecureSkipTLSVerify bool
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{entries: map[string]cachedClient{}}
+}
+
+func repoClientSettings(repo model.Repo) clientSettings {
+	return clientSettings{
+		url:                   repo.URL,
+		username:              repo.Username,
+		password:              repo.Password,
+		token:                 repo.Token,
+		caBundle:              repo.CABundle,
+		certData:              repo.CertData,
+		keyData:               repo.KeyData,
+		insecureSkipTLSVerify: repo.InsecureSkipTLSVerify,
+	}
+}
+
+// get returns the client of the repo, building it on first use or when the repo settings changed.
+func (c *clientCache) get(repo model.Repo) (*http.Client, error) {
+	settings := repoClientSettings(repo)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.entries[repo.Name]
+	if ok && cached.settings == settings {
+		return cached.client, nil
+	}
+
+	client, err := newHTTPClient(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		cached.client.CloseIdleConnections()
+	}
+	c.entries[repo.Name] = cachedClient{settings: settings, client: client}
+
+	return client, nil
+}
+
+// newHTTPClient builds a client that talks to the repo with its TLS settings and credentials.
+func newHTTPClient(repo model.Repo) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: repo.InsecureSkipTLSVerify,
+	}
+
+	if repo.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM([]byte(repo.CABundle)) {
+			return nil, fmt.Errorf("ca bundle of repo %s contains no valid certificate", repo.Name)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if repo.CertData != "" || repo.KeyData != "" {
+		cert, err := tls.X509KeyPair([]byte(repo.CertData), []byte(repo.KeyData))
+		if err != nil {
+			return nil, fmt.Errorf("client certificate of repo %s: %w", repo.Name, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = tlsConfig
+
+	repoUrl, err := url.Parse(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: httpTimeout,
+		Transport: &authTransport{
+			host:     repoUrl.Host,
+			username: repo.Username,
+			password: repo.Password,
+			token:    repo.Token,
+			base:     base,
+		},
+	}, nil
+}
+
+// authTransport adds the repo credentials to requests sent to the repo host only,
+// chart archives served from another host never see them.
+type authTransport struct {
+	host     string
+	username string
+	password string
+	token    string
+	base     http.RoundTripper
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the wrapped transport.
+func (t *authTransport) CloseIdleConnections() {
+	if closer, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host || req.Header.Get("Authorization") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	switch {
+	case t.token != "":
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	case t.username != "" || t.password != "":
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(t.username, t.password)
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,116 @@
+package helm
+
+import (
+	"bytes"
+	"chart-viewer/pkg/model"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const uploadKeyPrefix = "upload:"
+
+var ErrInvalidChart = errors.New("invalid chart archive")
+
+// uploadRecord is how an uploaded archive is kept in the repository, under its content digest.
+type uploadRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Archive []byte `json:"archive"`
+}
+
+func uploadKey(id string) string {
+	return uploadKeyPrefix + id
+}
+
+// UploadChart stores a packaged chart under the sha256 digest of its content, uploading the same archive twice returns the same ID.
+func (h helm) UploadChart(archive []byte) (model.UploadedChart, error) {
+	c, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return model.UploadedChart{}, fmt.Errorf("%w: %s", ErrInvalidChart, err)
+	}
+
+	id := fmt.Sprintf("%x", sha256.Sum256(archive))
+	record, err := json.Marshal(uploadRecord{
+		Name:    c.Metadata.Name,
+		Version: c.Metadata.Version,
+		Archive: archive,
+	})
+	if err != nil {
+		return model.UploadedChart{}, err
+	}
+
+	err = h.repository.Set(context.Background(), uploadKey(id), string(record), 0)
+	if err != nil {
+		return model.UploadedChart{}, err
+	}
+
+	return model.UploadedChart{
+		ID:      id,
+		Repo:    model.UploadRepoName,
+		Name:    c.Metadata.Name,
+		Version: c.Metadata.Version,
+	}, nil
+}
+
+func (h helm) getUploadRecord(id string) (uploadRecord, error) {
+	var record uploadRecord
+
+	stringified, found, err := h.repository.Get(context.Background(), uploadKey(id))
+	if err != nil {
+		return record, err
+	}
+
+	if !found {
+		return record, fmt.Errorf("uploaded chart %s not found", id)
+	}
+
+	err = json.Unmarshal([]byte(stringified), &record)
+	return record, err
+}
+
+// getUploadIndex lists the uploaded charts, keyed by upload ID like the chart names of a regular repo.
+func (h helm) getUploadIndex() (model.RepoDetailResponse, error) {
+	index := model.RepoDetailResponse{
+		ApiVersion: "v1",
+		Entries:    map[string][]model.ChartResponse{},
+	}
+
+	keys, err := h.repository.Keys(context.Background(), uploadKeyPrefix)
+	if err != nil {
+		return index, err
+	}
+
+	for _, key := range keys {
+		id := strings.TrimPrefix(key, uploadKeyPrefix)
+		record, err := h.getUploadRecord(id)
+		if err != nil {
+			return index, err
+		}
+
+		index.Entries[id] = []model.ChartResponse{
+			{Name: record.Name, Version: record.Version},
+		}
+	}
+
+	return index, nil
+}
+
+func (h helm) loadUploadedChart(id, chartVersion string) (*chart.Chart, error) {
+	record, err := h.getUploadRecord(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Version != chartVersion {
+		return nil, fmt.Errorf("uploaded chart %s has version %s, not %s", id, record.Version, chartVersion)
+	}
+
+	return loader.LoadArchive(bytes.NewReader(record.Archive))
+}
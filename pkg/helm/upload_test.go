@@ -0,0 +1,38 @@
+package helm_test
+
+import (
+	"chart-viewer/pkg/helm"
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHelm_UploadChart(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+	uploadsRepo := model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}
+
+	uploaded, err := h.UploadChart(packageChart(t, "testdata/sample"))
+	assert.NoError(t, err)
+	assert.Len(t, uploaded.ID, 64)
+	assert.Equal(t, model.UploadedChart{ID: uploaded.ID, Repo: "uploads", Name: "sample", Version: "0.1.0"}, uploaded)
+
+	index, err := h.GetIndex(uploadsRepo)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ChartResponse{{Name: "sample", Version: "0.1.0"}}, index.Entries[uploaded.ID])
+
+	err, values := h.GetValues(uploadsRepo, uploaded.ID, "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), values["replicaCount"])
+
+	err, _ = h.GetValues(uploadsRepo, uploaded.ID, "0.2.0")
+	assert.Error(t, err)
+}
+
+func TestHelm_UploadInvalidChart(t *testing.T) {
+	h := helm.NewHelmClient(repository.NewMemoryRepository(), 0)
+
+	_, err := h.UploadChart([]byte("not a chart"))
+	assert.True(t, errors.Is(err, helm.ErrInvalidChart))
+}
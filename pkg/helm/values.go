@@ -0,0 +1,68 @@
+package helm
+
+import (
+	"chart-viewer/pkg/model"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+// mergeValues writes the values documents to temporary files so they are merged exactly like helm template merges
+// its --values, --set and --set-string flags. The set-file contents are given in the request, they are set in code
+// rather than passed as --set-file flags, which would read the path following the key from the server.
+func mergeValues(options model.ValuesOptions) (map[string]interface{}, error) {
+	dir, err := ioutil.TempDir("", "chart-viewer-values")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	valueOption := &values.Options{
+		Values:       options.Set,
+		StringValues: options.SetString,
+	}
+
+	for i, document := range options.Values {
+		path := filepath.Join(dir, fmt.Sprintf("values-%d.yaml", i))
+		err = ioutil.WriteFile(path, []byte(document), 0600)
+		if err != nil {
+			return nil, err
+		}
+		valueOption.ValueFiles = append(valueOption.ValueFiles, path)
+	}
+
+	vals, err := valueOption.MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(options.SetFile))
+	for key := range options.SetFile {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// a key holding = or , would set other keys, or values read from the path that follows it
+		if key == "" || strings.ContainsAny(key, "=,") {
+			return nil, fmt.Errorf("%w: set-file key %q", ErrInvalidRenderOptions, key)
+		}
+
+		content := options.SetFile[key]
+		err = strvals.ParseIntoFile(key+"=-", vals, func([]rune) (interface{}, error) {
+			return content, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing set-file data: %w", err)
+		}
+	}
+
+	return vals, nil
+}
@@ -0,0 +1,26 @@
+package jsonpath
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Root is the path of the whole document.
+const Root = "$"
+
+var (
+	plainKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	keyEscaper    = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+)
+
+// Key returns the path of the key of the object at path, in dot notation like $.spec.replicas when the key is a
+// plain identifier and in bracket notation like $.metadata.labels['app.kubernetes.io/name'] otherwise, where a quote
+// or a backslash of the key is escaped with a backslash.
+func Key(path, key string) string {
+	if plainKeyRegex.MatchString(key) {
+		return path + "." + key
+	}
+
+	return fmt.Sprintf("%s['%s']", path, keyEscaper.Replace(key))
+}
@@ -0,0 +1,15 @@
+package jsonpath_test
+
+import (
+	"chart-viewer/pkg/jsonpath"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "$.spec", jsonpath.Key(jsonpath.Root, "spec"))
+	assert.Equal(t, "$.metadata.labels['app.kubernetes.io/name']", jsonpath.Key("$.metadata.labels", "app.kubernetes.io/name"))
+	assert.Equal(t, "$['1st']", jsonpath.Key(jsonpath.Root, "1st"))
+	assert.Equal(t, `$['it\'s']`, jsonpath.Key(jsonpath.Root, "it's"))
+	assert.Equal(t, `$['C:\\temp\\']`, jsonpath.Key(jsonpath.Root, `C:\temp\`))
+}
@@ -0,0 +1,274 @@
+// Package kubeapi builds the API data of a kube version from what a cluster reports about itself.
+package kubeapi
+
+import (
+	"bufio"
+	"bytes"
+	"chart-viewer/pkg/model"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ignoredKinds are the API machinery kinds every group version of an OpenAPI spec declares.
+var ignoredKinds = map[string]bool{
+	"APIGroup":        true,
+	"APIGroupList":    true,
+	"APIResourceList": true,
+	"APIVersions":     true,
+	"DeleteOptions":   true,
+	"Status":          true,
+	"WatchEvent":      true,
+}
+
+// virtualKinds are the kinds of create-only review and request APIs, they are never stored and so not deployable.
+var virtualKinds = map[string]bool{
+	"Eviction":                 true,
+	"LocalSubjectAccessReview": true,
+	"Scale":                    true,
+	"SelfSubjectAccessReview":  true,
+	"SelfSubjectReview":        true,
+	"SelfSubjectRulesReview":   true,
+	"SubjectAccessReview":      true,
+	"TokenRequest":             true,
+	"TokenReview":              true,
+}
+
+var (
+	apiVersionLineRegex = regexp.MustCompile(`^([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?v[0-9]+[a-z0-9]*$`)
+	releaseRegex        = regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)`)
+	apiPathPrefixRegex  = regexp.MustCompile(`^/(api|apis/[^/]+)/[^/]+/`)
+)
+
+type openAPISpec struct {
+	Swagger string `json:"swagger" yaml:"swagger"`
+	OpenAPI string `json:"openapi" yaml:"openapi"`
+	Info    struct {
+		Version string `json:"version" yaml:"version"`
+	} `json:"info" yaml:"info"`
+	Definitions map[string]openAPISchema `json:"definitions" yaml:"definitions"`
+	Components  struct {
+		Schemas map[string]openAPISchema `json:"schemas" yaml:"schemas"`
+	} `json:"components" yaml:"components"`
+	Paths map[string]map[string]openAPIOperation `json:"paths" yaml:"paths"`
+}
+
+type groupVersionKind struct {
+	Group   string `json:"group" yaml:"group"`
+	Version string `json:"version" yaml:"version"`
+	Kind    string `json:"kind" yaml:"kind"`
+}
+
+func (gvk groupVersionKind) apiVersion() string {
+	if gvk.Group == "" {
+		return gvk.Version
+	}
+	return gvk.Group + "/" + gvk.Version
+}
+
+type openAPISchema struct {
+	GroupVersionKinds []groupVersionKind `json:"x-kubernetes-group-version-kind" yaml:"x-kubernetes-group-version-kind"`
+}
+
+type openAPIOperation struct {
+	GroupVersionKind *groupVersionKind `json:"x-kubernetes-group-version-kind" yaml:"x-kubernetes-group-version-kind"`
+}
+
+// UnmarshalJSON ignores the path items which are not operations, like the parameters list.
+func (o *openAPIOperation) UnmarshalJSON(data []byte) error {
+	var operation struct {
+		GroupVersionKind *groupVersionKind `json:"x-kubernetes-group-version-kind"`
+	}
+	if json.Unmarshal(data, &operation) == nil {
+		o.GroupVersionKind = operation.GroupVersionKind
+	}
+	return nil
+}
+
+// UnmarshalYAML ignores the path items which are not operations, like the parameters list.
+func (o *openAPIOperation) UnmarshalYAML(node *yaml.Node) error {
+	var operation struct {
+		GroupVersionKind *groupVersionKind `yaml:"x-kubernetes-group-version-kind"`
+	}
+	if node.Decode(&operation) == nil {
+		o.GroupVersionKind = operation.GroupVersionKind
+	}
+	return nil
+}
+
+// Parse merges the dumps of a cluster into the kube version name. A dump is either the output of
+// kubectl api-versions or an OpenAPI v2 or v3 spec, as served at /openapi/v2 and /openapi/v3/{group}/{version}.
+// Only OpenAPI specs list kinds, and the release of the cluster is read from the info version of a v2 spec.
+func Parse(name string, dumps ...[]byte) (model.KubernetesAPIVersion, error) {
+	kinds := make(map[string]map[string]bool)
+	release := ""
+
+	for i, dump := range dumps {
+		spec, isSpec, err := parseOpenAPISpec(dump)
+		if err != nil {
+			return model.KubernetesAPIVersion{}, fmt.Errorf("dump %d: %w", i+1, err)
+		}
+
+		if !isSpec {
+			apiVersions, err := parseAPIVersions(dump)
+			if err != nil {
+				return model.KubernetesAPIVersion{}, fmt.Errorf("dump %d: %w", i+1, err)
+			}
+
+			for _, apiVersion := range apiVersions {
+				if kinds[apiVersion] == nil {
+					kinds[apiVersion] = make(map[string]bool)
+				}
+			}
+			continue
+		}
+
+		if submatch := releaseRegex.FindStringSubmatch(spec.Info.Version); submatch != nil && spec.Swagger != "" {
+			release = submatch[1] + "." + submatch[2]
+		}
+
+		subresourceKinds := parseSubresourceKinds(spec.Paths)
+		for _, schemas := range []map[string]openAPISchema{spec.Definitions, spec.Components.Schemas} {
+			for _, schema := range schemas {
+				for _, gvk := range schema.GroupVersionKinds {
+					if ignoredKinds[gvk.Kind] || virtualKinds[gvk.Kind] || subresourceKinds[gvk] ||
+						strings.HasSuffix(gvk.Kind, "List") {
+						continue
+					}
+
+					apiVersion := gvk.apiVersion()
+
+					if kinds[apiVersion] == nil {
+						kinds[apiVersion] = make(map[string]bool)
+					}
+					kinds[apiVersion][gvk.Kind] = true
+				}
+			}
+		}
+	}
+
+	if len(kinds) == 0 {
+		return model.KubernetesAPIVersion{}, fmt.Errorf("no api versions found in the dumps of %s", name)
+	}
+
+	kubeVersion := model.KubernetesAPIVersion{
+		KubeVersion: name,
+		Release:     release,
+		APIVersions: make([]string, 0, len(kinds)),
+		Kinds:       make(map[string][]string),
+	}
+
+	for apiVersion, apiVersionKinds := range kinds {
+		kubeVersion.APIVersions = append(kubeVersion.APIVersions, apiVersion)
+		if len(apiVersionKinds) == 0 {
+			continue
+		}
+
+		for kind := range apiVersionKinds {
+			kubeVersion.Kinds[apiVersion] = append(kubeVersion.Kinds[apiVersion], kind)
+		}
+		sort.Strings(kubeVersion.Kinds[apiVersion])
+	}
+	sort.Strings(kubeVersion.APIVersions)
+
+	if len(kubeVersion.Kinds) == 0 {
+		kubeVersion.Kinds = nil
+	}
+
+	return kubeVersion, nil
+}
+
+// parseOpenAPISpec reads a JSON or YAML OpenAPI spec, isSpec is false for any other content.
+func parseOpenAPISpec(dump []byte) (openAPISpec, bool, error) {
+	var spec openAPISpec
+
+	trimmed := bytes.TrimSpace(dump)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		err := json.Unmarshal(trimmed, &spec)
+		if err != nil {
+			return openAPISpec{}, false, fmt.Errorf("parsing OpenAPI spec: %w", err)
+		}
+	case bytes.HasPrefix(trimmed, []byte("swagger:")), bytes.HasPrefix(trimmed, []byte("openapi:")):
+		err := yaml.Unmarshal(trimmed, &spec)
+		if err != nil {
+			return openAPISpec{}, false, fmt.Errorf("parsing OpenAPI spec: %w", err)
+		}
+	default:
+		return openAPISpec{}, false, nil
+	}
+
+	if spec.Swagger == "" && spec.OpenAPI == "" {
+		return openAPISpec{}, false, fmt.Errorf("JSON document is not an OpenAPI spec, swagger or openapi version is missing")
+	}
+
+	return spec, true, nil
+}
+
+// parseSubresourceKinds lists the kinds the paths of a spec only serve as a subresource, like pods/eviction,
+// a kind also served by a resource path is left out.
+func parseSubresourceKinds(paths map[string]map[string]openAPIOperation) map[groupVersionKind]bool {
+	subresourceKinds := make(map[groupVersionKind]bool)
+	resourceKinds := make(map[groupVersionKind]bool)
+
+	for path, operations := range paths {
+		for _, operation := range operations {
+			if operation.GroupVersionKind == nil {
+				continue
+			}
+
+			gvk := *operation.GroupVersionKind
+			if strings.Contains(resourceName(path), "/") {
+				subresourceKinds[gvk] = true
+			} else {
+				resourceKinds[gvk] = true
+			}
+		}
+	}
+
+	for gvk := range resourceKinds {
+		delete(subresourceKinds, gvk)
+	}
+
+	return subresourceKinds
+}
+
+// resourceName reads the resource name of a path, /api/v1/namespaces/{namespace}/pods/{name}/eviction is pods/eviction.
+func resourceName(path string) string {
+	path = apiPathPrefixRegex.ReplaceAllString(path, "")
+	path = strings.TrimPrefix(path, "watch/")
+	path = strings.TrimPrefix(path, "namespaces/{namespace}/")
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") {
+			segments = append(segments, segment)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// parseAPIVersions reads the output of kubectl api-versions, one apiVersion per line.
+func parseAPIVersions(dump []byte) ([]string, error) {
+	var apiVersions []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(dump))
+	for line := 1; scanner.Scan(); line++ {
+		apiVersion := strings.TrimSpace(scanner.Text())
+		if apiVersion == "" {
+			continue
+		}
+
+		if !apiVersionLineRegex.MatchString(apiVersion) {
+			return nil, fmt.Errorf("line %d: %q is not an api version", line, apiVersion)
+		}
+		apiVersions = append(apiVersions, apiVersion)
+	}
+
+	return apiVersions, scanner.Err()
+}
@@ -0,0 +1,62 @@
+package kubeapi_test
+
+import (
+	"chart-viewer/pkg/kubeapi"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func readDump(t *testing.T, path string) []byte {
+	dump, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return dump
+}
+
+func TestParse_APIVersions(t *testing.T) {
+	kubeVersion, err := kubeapi.Parse("prod-cluster-1.24", readDump(t, "testdata/api-versions.txt"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, model.KubernetesAPIVersion{
+		KubeVersion: "prod-cluster-1.24",
+		APIVersions: []string{
+			"admissionregistration.k8s.io/v1",
+			"apps/v1",
+			"batch/v1",
+			"monitoring.coreos.com/v1",
+			"networking.k8s.io/v1",
+			"policy/v1",
+			"v1",
+		},
+	}, kubeVersion)
+}
+
+func TestParse_OpenAPISpecs(t *testing.T) {
+	kubeVersion, err := kubeapi.Parse("prod-cluster-1.24", readDump(t, "testdata/openapi-v2.json"), readDump(t, "testdata/openapi-v3.yaml"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, model.KubernetesAPIVersion{
+		KubeVersion: "prod-cluster-1.24",
+		Release:     "1.24",
+		APIVersions: []string{"apps/v1", "monitoring.coreos.com/v1", "policy/v1", "stable.example.com/v1alpha1", "v1"},
+		Kinds: map[string][]string{
+			"apps/v1":                     {"Deployment"},
+			"monitoring.coreos.com/v1":    {"ServiceMonitor"},
+			"policy/v1":                   {"PodDisruptionBudget"},
+			"stable.example.com/v1alpha1": {"Widget"},
+			"v1":                          {"Service"},
+		},
+	}, kubeVersion)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, dump := range []string{
+		"",
+		"apps/v1\nNAME SHORTNAMES APIVERSION\n",
+		`{"kind": "APIGroupList"}`,
+	} {
+		_, err := kubeapi.Parse("prod", []byte(dump))
+		assert.Error(t, err, dump)
+	}
+}
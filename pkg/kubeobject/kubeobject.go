@@ -0,0 +1,74 @@
+package kubeobject
+
+import (
+	"bytes"
+	"chart-viewer/pkg/model"
+	"errors"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse decodes every YAML document of a manifest, empty documents and documents without an apiVersion are skipped.
+func Parse(content string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+
+	decoder := yaml.NewDecoder(bytes.NewBufferString(content))
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if apiVersion, _ := object["apiVersion"].(string); apiVersion == "" {
+			continue
+		}
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// ID returns the apiVersion, kind, namespace and name of a decoded resource, the missing ones are empty.
+func ID(object map[string]interface{}) model.ResourceID {
+	return model.ResourceID{
+		APIVersion: NestedString(object, "apiVersion"),
+		Kind:       NestedString(object, "kind"),
+		Namespace:  NestedString(object, "metadata", "namespace"),
+		Name:       NestedString(object, "metadata", "name"),
+	}
+}
+
+// NestedValue returns the value at the path of keys, ok is false when one of the keys is missing.
+func NestedValue(object map[string]interface{}, keys ...string) (interface{}, bool) {
+	var value interface{} = object
+	for _, key := range keys {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func NestedMap(object map[string]interface{}, keys ...string) (map[string]interface{}, bool) {
+	value, _ := NestedValue(object, keys...)
+	m, ok := value.(map[string]interface{})
+	return m, ok
+}
+
+func NestedString(object map[string]interface{}, keys ...string) string {
+	value, _ := NestedValue(object, keys...)
+	s, _ := value.(string)
+	return s
+}
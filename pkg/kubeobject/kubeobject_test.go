@@ -0,0 +1,41 @@
+package kubeobject_test
+
+import (
+	"chart-viewer/pkg/kubeobject"
+	"chart-viewer/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	objects, err := kubeobject.Parse("---\n# empty\n---\nkind: List\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n  namespace: apps\n---\napiVersion: batch/v1\nkind: Job\nmetadata:\n  generateName: migrate-\n")
+	assert.NoError(t, err)
+
+	var ids []model.ResourceID
+	for _, object := range objects {
+		ids = append(ids, kubeobject.ID(object))
+	}
+	assert.Equal(t, []model.ResourceID{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: "apps", Name: "app"},
+		{APIVersion: "batch/v1", Kind: "Job"},
+	}, ids)
+
+	_, err = kubeobject.Parse("kind: [Deployment")
+	assert.Error(t, err)
+}
+
+func TestNestedValue(t *testing.T) {
+	object := map[string]interface{}{"spec": map[string]interface{}{"replicas": 3, "selector": "app"}}
+
+	value, ok := kubeobject.NestedValue(object, "spec", "replicas")
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+
+	_, ok = kubeobject.NestedValue(object, "spec", "selector", "app")
+	assert.False(t, ok)
+
+	_, ok = kubeobject.NestedMap(object, "spec")
+	assert.True(t, ok)
+	assert.Equal(t, "app", kubeobject.NestedString(object, "spec", "selector"))
+	assert.Equal(t, "", kubeobject.NestedString(object, "spec", "replicas"))
+}
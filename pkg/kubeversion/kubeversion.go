@@ -0,0 +1,58 @@
+// Package kubeversion parses the Kubernetes release versions used to render charts and to look up API data.
+package kubeversion
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid kube version")
+
+// Version is a Kubernetes release, the patch is zero when it was not given.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// Parse accepts versions like 1.21, v1.21 or 1.21.3.
+func Parse(version string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("%w %q, expected major.minor[.patch]", ErrInvalid, version)
+	}
+
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		number, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q, expected major.minor[.patch]", ErrInvalid, version)
+		}
+		numbers[i] = int(number)
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// MinorRelease returns the minor release like 1.21, which kube versions are named after.
+func (v Version) MinorRelease() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Before reports whether v is an older release than other.
+func (v Version) Before(other Version) bool {
+	switch {
+	case v.Major != other.Major:
+		return v.Major < other.Major
+	case v.Minor != other.Minor:
+		return v.Minor < other.Minor
+	default:
+		return v.Patch < other.Patch
+	}
+}
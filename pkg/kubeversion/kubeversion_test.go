@@ -0,0 +1,36 @@
+package kubeversion_test
+
+import (
+	"chart-viewer/pkg/kubeversion"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for version, expected := range map[string]kubeversion.Version{
+		"1.21":    {Major: 1, Minor: 21},
+		"v1.21":   {Major: 1, Minor: 21},
+		"1.21.3":  {Major: 1, Minor: 21, Patch: 3},
+		"v1.21.3": {Major: 1, Minor: 21, Patch: 3},
+	} {
+		actual, err := kubeversion.Parse(version)
+		assert.NoError(t, err, version)
+		assert.Equal(t, expected, actual, version)
+	}
+
+	for _, version := range []string{"", "1", "1.21.3.4", "1.x", "1.21.3-eks", "1.-21", "prod-cluster"} {
+		_, err := kubeversion.Parse(version)
+		assert.True(t, errors.Is(err, kubeversion.ErrInvalid), version)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	version := kubeversion.Version{Major: 1, Minor: 21, Patch: 3}
+
+	assert.Equal(t, "1.21", version.MinorRelease())
+	assert.Equal(t, "v1.21.3", version.String())
+	assert.True(t, version.Before(kubeversion.Version{Major: 1, Minor: 22}))
+	assert.True(t, kubeversion.Version{Major: 1, Minor: 21}.Before(version))
+	assert.False(t, version.Before(version))
+}
@@ -1,10 +1,71 @@
 package model
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	RepoTypeHTTP   = "http"
+	RepoTypeOCI    = "oci"
+	RepoTypeUpload = "upload"
+
+	// UploadRepoName is the virtual repo holding uploaded chart archives, its chart names are the upload IDs.
+	UploadRepoName = "uploads"
+)
 
 type Repo struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// Type is http for classic repos serving an index.yaml or oci for OCI registries, empty means http.
+	Type string `json:"type,omitempty"`
+	// Charts lists the chart names of an OCI repo, needed when the registry does not serve its catalog.
+	Charts    []string `json:"charts,omitempty"`
+	PlainHTTP bool     `json:"plain_http,omitempty"`
+
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	// CABundle, CertData and KeyData are PEM encoded.
+	CABundle              string `json:"ca_bundle,omitempty"`
+	CertData              string `json:"cert_data,omitempty"`
+	KeyData               string `json:"key_data,omitempty"`
+	InsecureSkipTLSVerify bool   `json:"insecure_skip_tls_verify,omitempty"`
+}
+
+func (r Repo) IsOCI() bool {
+	return r.Type == RepoTypeOCI || strings.HasPrefix(r.URL, "oci://")
+}
+
+// Redacted returns a copy of the repo without its credentials, safe to return to API clients.
+func (r Repo) Redacted() Repo {
+	r.Username = ""
+	r.Password = ""
+	r.Token = ""
+	r.KeyData = ""
+	return r
+}
+
+// WithCredentialsFrom fills the credentials that are not set on r from other,
+// so an update that only changes the URL keeps the stored credentials.
+func (r Repo) WithCredentialsFrom(other Repo) Repo {
+	if r.Username == "" && r.Password == "" {
+		r.Username = other.Username
+		r.Password = other.Password
+	}
+	if r.Token == "" {
+		r.Token = other.Token
+	}
+	if r.CABundle == "" {
+		r.CABundle = other.CABundle
+	}
+	if r.CertData == "" && r.KeyData == "" {
+		r.CertData = other.CertData
+		r.KeyData = other.KeyData
+	}
+	return r
 }
 
 func (r Repo) MarshalBinary() ([]byte, error) {
@@ -12,6 +73,13 @@ func (r Repo) MarshalBinary() ([]byte, error) {
 	return []byte(s), nil
 }
 
+type UploadedChart struct {
+	ID      string `json:"id"`
+	Repo    string `json:"repo"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
 type Chart struct {
 	Name     string   `json:"name"`
 	Versions []string `json:"versions"`
@@ -23,8 +91,25 @@ type Template struct {
 }
 
 type ChartDetail struct {
-	Values    map[string]interface{} `json:"values"`
-	Templates []Template             `json:"templates"`
+	Values       map[string]interface{} `json:"values"`
+	Templates    []Template             `json:"templates"`
+	Dependencies []Subchart             `json:"dependencies"`
+}
+
+// Subchart is a dependency of a chart. ValuesScope is the path of its values in the values of the top chart,
+// the alias or name of every chart on the way. Missing dependencies are declared but not packaged in charts/.
+type Subchart struct {
+	Name         string                 `json:"name"`
+	Version      string                 `json:"version"`
+	Repository   string                 `json:"repository,omitempty"`
+	Condition    string                 `json:"condition,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Alias        string                 `json:"alias,omitempty"`
+	ValuesScope  string                 `json:"valuesScope"`
+	Missing      bool                   `json:"missing,omitempty"`
+	Values       map[string]interface{} `json:"values,omitempty"`
+	Templates    []Template             `json:"templates,omitempty"`
+	Dependencies []Subchart             `json:"dependencies,omitempty"`
 }
 
 type RepoDetailResponse struct {
@@ -33,9 +118,14 @@ type RepoDetailResponse struct {
 }
 
 type ChartResponse struct {
-	Name    string   `yaml:"name"`
-	Version string   `yaml:"version"`
-	URLs    []string `yaml:"urls"`
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	URLs        []string `yaml:"urls"`
+	Description string   `yaml:"description"`
+	AppVersion  string   `yaml:"appVersion"`
+	Deprecated  bool     `yaml:"deprecated"`
+	Created     string   `yaml:"created"`
+	Digest      string   `yaml:"digest"`
 }
 
 type Manifest struct {
@@ -43,26 +133,367 @@ type Manifest struct {
 	Content string `json:"content"`
 }
 
+// ValuesDocuments is an ordered list of YAML values documents, later documents override earlier ones.
+// It unmarshals from a single JSON string as well as from an array of strings.
+type ValuesDocuments []string
+
+func (d *ValuesDocuments) UnmarshalJSON(data []byte) error {
+	var document string
+	if err := json.Unmarshal(data, &document); err == nil {
+		*d = ValuesDocuments{document}
+		return nil
+	}
+
+	var documents []string
+	if err := json.Unmarshal(data, &documents); err != nil {
+		return fmt.Errorf("values must be a string or an array of strings: %w", err)
+	}
+
+	*d = documents
+	return nil
+}
+
+// ValuesOptions mirrors the values flags of helm template. SetFile maps a key to the file content to set it to.
+type ValuesOptions struct {
+	Values    ValuesDocuments   `json:"values"`
+	Set       []string          `json:"set,omitempty"`
+	SetString []string          `json:"setString,omitempty"`
+	SetFile   map[string]string `json:"setFile,omitempty"`
+}
+
+func (o ValuesOptions) HasOverrides() bool {
+	return len(o.Set) != 0 || len(o.SetString) != 0 || len(o.SetFile) != 0
+}
+
+// RenderOptions mirrors the flags of helm template that change what a chart renders to. KubeVersion and
+// APIVersions are the capabilities of the target cluster.
+type RenderOptions struct {
+	ValuesOptions
+	ReleaseName  string   `json:"releaseName,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+	KubeVersion  string   `json:"kubeVersion,omitempty"`
+	APIVersions  []string `json:"apiVersions,omitempty"`
+	IncludeCRDs  bool     `json:"includeCRDs,omitempty"`
+	DisableHooks bool     `json:"disableHooks,omitempty"`
+	IsUpgrade    bool     `json:"isUpgrade,omitempty"`
+}
+
+func (o RenderOptions) HasSettings() bool {
+	return o.ReleaseName != "" || o.Namespace != "" || o.KubeVersion != "" || len(o.APIVersions) != 0 ||
+		o.IncludeCRDs || o.DisableHooks || o.IsUpgrade
+}
+
 type ManifestResponse struct {
 	URL       string     `json:"url"`
 	Manifests []Manifest `json:"manifests"`
 }
 
+// KubernetesAPIVersion is the API served by a kube version. Kinds lists the kinds served by each apiVersion,
+// apiVersions missing from it are assumed to serve every kind. Kube versions named after a cluster rather than a
+// release, like prod-cluster-1.24, set the Kubernetes release the cluster runs in Release.
 type KubernetesAPIVersion struct {
-	KubeVersion string   `json:"kube_version"`
-	APIVersions []string `json:"api_versions"`
+	KubeVersion string              `json:"kube_version"`
+	Release     string              `json:"release,omitempty"`
+	APIVersions []string            `json:"api_versions"`
+	Kinds       map[string][]string `json:"kinds,omitempty"`
+}
+
+// ReleaseVersion is the Kubernetes release of the kube version, its name when it is named after the release.
+func (k KubernetesAPIVersion) ReleaseVersion() string {
+	if k.Release != "" {
+		return k.Release
+	}
+	return k.KubeVersion
 }
 
 type KubeResourceCommonSpec struct {
 	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
 }
 
+// AnalyticsResult is the analysis of a template, Subchart is the values scope of the subchart holding the template.
 type AnalyticsResult struct {
 	Template
-	Compatible bool `json:"compatible"`
+	Compatible bool   `json:"compatible"`
+	Subchart   string `json:"subchart,omitempty"`
 }
 
 type AnalyticResponse struct {
-	Values    map[string]interface{} `json:"values"`
-	Templates []AnalyticsResult      `json:"templates"`
+	Values       map[string]interface{} `json:"values"`
+	Templates    []AnalyticsResult      `json:"templates"`
+	Dependencies []Subchart             `json:"dependencies"`
+}
+
+// ResourceAnalysis is the compatibility of a rendered resource, Manifest is the template it was rendered from.
+// ServedKinds are the kinds the target kube version serves in the apiVersion of the resource.
+type ResourceAnalysis struct {
+	Manifest    string     `json:"manifest"`
+	Resource    ResourceID `json:"resource"`
+	Compatible  bool       `json:"compatible"`
+	ServedKinds []string   `json:"servedKinds,omitempty"`
+}
+
+type ManifestAnalysisResponse struct {
+	KubeVersion  string               `json:"kubeVersion"`
+	Resources    []ResourceAnalysis   `json:"resources"`
+	Deprecations []DeprecationFinding `json:"deprecations"`
+}
+
+// APIDeprecation is the deprecation of a group/version/kind, the releases are Kubernetes minor versions like 1.22.
+// Replacement is the apiVersion to migrate to, empty when the kind is gone without replacement.
+type APIDeprecation struct {
+	Group        string `json:"group"`
+	Version      string `json:"version"`
+	Kind         string `json:"kind"`
+	DeprecatedIn string `json:"deprecated_in,omitempty"`
+	RemovedIn    string `json:"removed_in,omitempty"`
+	Replacement  string `json:"replacement,omitempty"`
+}
+
+const (
+	APIDeprecated = "deprecated"
+	APIRemoved    = "removed"
+)
+
+// DeprecationFinding is a rendered resource using an API that is deprecated or removed in the target kube version.
+type DeprecationFinding struct {
+	Manifest     string     `json:"manifest"`
+	Resource     ResourceID `json:"resource"`
+	Status       string     `json:"status"`
+	DeprecatedIn string     `json:"deprecatedIn,omitempty"`
+	RemovedIn    string     `json:"removedIn,omitempty"`
+	Replacement  string     `json:"replacement,omitempty"`
+	Message      string     `json:"message"`
+}
+
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+// PolicyFinding is a policy rule violated by a rendered resource, Path is the JSON path of the offending field like
+// $.spec.template.spec.containers[0].image.
+type PolicyFinding struct {
+	Rule     string     `json:"rule"`
+	Severity string     `json:"severity"`
+	Manifest string     `json:"manifest"`
+	Resource ResourceID `json:"resource"`
+	Path     string     `json:"path"`
+	Message  string     `json:"message"`
+}
+
+// PolicyRule is a policy check of rendered resources. User-defined rules are expressions over each resource of the
+// Kinds, or of every kind when empty, Source is the file they are loaded from or builtin.
+type PolicyRule struct {
+	Name        string   `json:"name" yaml:"name"`
+	Severity    string   `json:"severity" yaml:"severity"`
+	Description string   `json:"description,omitempty" yaml:"description"`
+	Kinds       []string `json:"kinds,omitempty" yaml:"kinds"`
+	Expression  string   `json:"expression,omitempty" yaml:"expression"`
+	Message     string   `json:"message,omitempty" yaml:"message"`
+	Path        string   `json:"path,omitempty" yaml:"path"`
+	Source      string   `json:"source" yaml:"-"`
+}
+
+type PolicyCheckResponse struct {
+	Findings []PolicyFinding `json:"findings"`
+}
+
+// ImageReference is a container image split into its parts, normalized like docker pull does: images without a
+// registry are pulled from docker.io, and images without a tag or digest from the latest tag.
+type ImageReference struct {
+	Image      string `json:"image"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// ContainerImage is the image of a container of a rendered workload, ContainerType is containers, initContainers or
+// ephemeralContainers.
+type ContainerImage struct {
+	ImageReference
+	Manifest      string     `json:"manifest"`
+	Resource      ResourceID `json:"resource"`
+	Container     string     `json:"container"`
+	ContainerType string     `json:"containerType"`
+}
+
+type ImageInventoryResponse struct {
+	Images []ContainerImage `json:"images"`
+}
+
+// RBACClusterScope is the scope of permissions granted in every namespace and on cluster scoped resources.
+const RBACClusterScope = "cluster"
+
+// RBACPermission is a row of the permission matrix of a chart: the verbs a role grants on a resource within a scope,
+// the cluster or a namespace, and the subjects bound to the role there. Resource is a resource like pods, a
+// subresource like pods/log or a non-resource URL like /metrics, Role is like ClusterRole name or Role namespace/name.
+type RBACPermission struct {
+	Scope         string   `json:"scope"`
+	APIGroup      string   `json:"apiGroup"`
+	Resource      string   `json:"resource"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Verbs         []string `json:"verbs"`
+	Role          string   `json:"role"`
+	Subjects      []string `json:"subjects,omitempty"`
+	Wildcard      bool     `json:"wildcard,omitempty"`
+}
+
+// RBACFinding highlights a risky grant, like wildcards or the permissions of cluster-admin.
+type RBACFinding struct {
+	Severity string `json:"severity"`
+	Role     string `json:"role"`
+	Scope    string `json:"scope"`
+	Message  string `json:"message"`
+}
+
+// RBACReport is the permission matrix of a chart with the service accounts it creates and its risky grants.
+type RBACReport struct {
+	Permissions     []RBACPermission `json:"permissions"`
+	ServiceAccounts []string         `json:"serviceAccounts"`
+	Findings        []RBACFinding    `json:"findings"`
+}
+
+// RBACPermissionChange is a row of the permission matrix added, removed or modified between two chart versions,
+// modified rows list the verbs and subjects they gained and lost.
+type RBACPermissionChange struct {
+	Status          string         `json:"status"`
+	Permission      RBACPermission `json:"permission"`
+	AddedVerbs      []string       `json:"addedVerbs,omitempty"`
+	RemovedVerbs    []string       `json:"removedVerbs,omitempty"`
+	AddedSubjects   []string       `json:"addedSubjects,omitempty"`
+	RemovedSubjects []string       `json:"removedSubjects,omitempty"`
+}
+
+// RBACDiffResponse compares the permission matrices of two chart versions, Findings are the risky grants of the to
+// version that the from version does not have.
+type RBACDiffResponse struct {
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	Changes  []RBACPermissionChange `json:"changes"`
+	Findings []RBACFinding          `json:"findings"`
+}
+
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+	DiffChanged  = "changed"
+	DiffRenamed  = "renamed"
+)
+
+// FileDiff is the change of one file between two chart versions, Diff holds the unified diff.
+type FileDiff struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Diff   string `json:"diff"`
+}
+
+type TemplateDiffResponse struct {
+	From  string     `json:"from"`
+	To    string     `json:"to"`
+	Files []FileDiff `json:"files"`
+}
+
+// ResourceID identifies a rendered resource across chart versions.
+type ResourceID struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (r ResourceID) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s %s", r.APIVersion, r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s %s/%s", r.APIVersion, r.Kind, r.Namespace, r.Name)
+}
+
+// FieldChange is a change of one field, Path is a JSON path like $.spec.replicas.
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Status string      `json:"status"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+type ResourceDiff struct {
+	Resource ResourceID    `json:"resource"`
+	Status   string        `json:"status"`
+	Changes  []FieldChange `json:"changes,omitempty"`
+}
+
+// ManifestDiffResponse lists the changed resources, Warnings the resources that could not be compared.
+type ManifestDiffResponse struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Resources []ResourceDiff `json:"resources"`
+	Warnings  []string       `json:"warnings,omitempty"`
+}
+
+// ValueChange is a change of one default value, Path uses the --set notation. Renamed values keep their old path in OldPath.
+type ValueChange struct {
+	Path    string      `json:"path"`
+	OldPath string      `json:"oldPath,omitempty"`
+	Status  string      `json:"status"`
+	Old     interface{} `json:"old"`
+	New     interface{} `json:"new"`
+}
+
+type ValuesDiffResponse struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Changes []ValueChange `json:"changes"`
+}
+
+// SchemaError is a violation of the values.schema.json of a chart, Path is a JSON path like $.image.tag.
+type SchemaError struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type ValuesValidationResponse struct {
+	Valid  bool          `json:"valid"`
+	Errors []SchemaError `json:"errors"`
+}
+
+type ChartMaintainer struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type ChartDependency struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version"`
+	Repository string   `json:"repository,omitempty"`
+	Condition  string   `json:"condition,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Alias      string   `json:"alias,omitempty"`
+}
+
+// ChartMetadata is the Chart.yaml of a chart version, Created, Digest and URLs come from the repo index.
+type ChartMetadata struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	APIVersion   string            `json:"apiVersion"`
+	Type         string            `json:"type"`
+	Description  string            `json:"description,omitempty"`
+	AppVersion   string            `json:"appVersion,omitempty"`
+	KubeVersion  string            `json:"kubeVersion,omitempty"`
+	Home         string            `json:"home,omitempty"`
+	Icon         string            `json:"icon,omitempty"`
+	Keywords     []string          `json:"keywords,omitempty"`
+	Sources      []string          `json:"sources,omitempty"`
+	Maintainers  []ChartMaintainer `json:"maintainers,omitempty"`
+	Deprecated   bool              `json:"deprecated"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Dependencies []ChartDependency `json:"dependencies,omitempty"`
+	Created      string            `json:"created,omitempty"`
+	Digest       string            `json:"digest,omitempty"`
+	URLs         []string          `json:"urls,omitempty"`
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("chart-viewer")
+
+// Each bolt value is prefixed with its expiry as unix nanoseconds, zero means it never expires.
+const boltExpiryLength = 8
+
+type boltRepository struct {
+	db *bolt.DB
+}
+
+// NewBoltRepository stores everything in a single BoltDB file on disk.
+func NewBoltRepository(path string) (Repository, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return boltRepository{
+		db: db,
+	}, nil
+}
+
+func (r boltRepository) Get(ctx context.Context, key string) (string, bool, error) {
+	var (
+		value string
+		found bool
+	)
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(boltBucket).Get([]byte(key))
+		if raw == nil || boltExpired(raw, time.Now()) {
+			return nil
+		}
+
+		value = string(raw[boltExpiryLength:])
+		found = true
+		return nil
+	})
+
+	return value, found, err
+}
+
+func (r boltRepository) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	raw := make([]byte, boltExpiryLength+len(value))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(raw, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	copy(raw[boltExpiryLength:], value)
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), raw)
+	})
+}
+
+func (r boltRepository) Delete(ctx context.Context, key string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+}
+
+func (r boltRepository) Exists(ctx context.Context, key string) (bool, error) {
+	_, found, err := r.Get(ctx, key)
+	return found, err
+}
+
+func (r boltRepository) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	now := time.Now()
+
+	err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltBucket).Cursor()
+		p := []byte(prefix)
+		for k, v := cursor.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = cursor.Next() {
+			if boltExpired(v, now) {
+				continue
+			}
+			keys = append(keys, string(k))
+		}
+
+		return nil
+	})
+
+	return keys, err
+}
+
+func boltExpired(raw []byte, now time.Time) bool {
+	if len(raw) < boltExpiryLength {
+		return true
+	}
+
+	expiresAt := int64(binary.BigEndian.Uint64(raw[:boltExpiryLength]))
+	return expiresAt != 0 && now.UnixNano() >= expiresAt
+}
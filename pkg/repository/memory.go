@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+type memoryRepository struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+// NewMemoryRepository keeps everything in process memory, the content is lost when the process exits.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		items: map[string]memoryItem{},
+	}
+}
+
+func (r *memoryRepository) Get(ctx context.Context, key string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, ok := r.items[key]
+	if !ok || item.expired(time.Now()) {
+		return "", false, nil
+	}
+
+	return item.value, true, nil
+}
+
+func (r *memoryRepository) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+	r.items[key] = item
+
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.items, key)
+	return nil
+}
+
+func (r *memoryRepository) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok, err := r.Get(ctx, key)
+	return ok, err
+}
+
+func (r *memoryRepository) Keys(ctx context.Context, prefix string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var keys []string
+	for key, item := range r.items {
+		if item.expired(now) {
+			delete(r.items, key)
+			continue
+		}
+
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
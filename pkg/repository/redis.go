@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const redisScanCount = 100
+
+var redisPatternEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+type redisRepository struct {
+	redisClient *redis.Client
+}
+
+func NewRedisRepository(redisAddress string) (Repository, error) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: redisAddress,
+	})
+
+	status := redisClient.Ping()
+	err := status.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return redisRepository{
+		redisClient: redisClient,
+	}, nil
+}
+
+func (r redisRepository) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.redisClient.WithContext(ctx).Get(key).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, true, nil
+}
+
+func (r redisRepository) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return r.redisClient.WithContext(ctx).Set(key, value, ttl).Err()
+}
+
+func (r redisRepository) Delete(ctx context.Context, key string) error {
+	return r.redisClient.WithContext(ctx).Del(key).Err()
+}
+
+func (r redisRepository) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := r.redisClient.WithContext(ctx).Exists(key).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r redisRepository) Keys(ctx context.Context, prefix string) ([]string, error) {
+	pattern := redisPatternEscaper.Replace(prefix) + "*"
+	iterator := r.redisClient.WithContext(ctx).Scan(0, pattern, redisScanCount).Iterator()
+
+	var keys []string
+	for iterator.Next() {
+		keys = append(keys, iterator.Val())
+	}
+
+	return keys, iterator.Err()
+}
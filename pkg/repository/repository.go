@@ -1,39 +1,43 @@
 package repository
 
 import (
-	"github.com/go-redis/redis"
+	"context"
+	"fmt"
+	"time"
 )
 
+const (
+	StorageRedis  = "redis"
+	StorageMemory = "memory"
+	StorageBolt   = "bolt"
+)
+
+// Repository is a key value store. A zero ttl keeps the value until it is deleted.
 type Repository interface {
-	Set(string, string)
-	Get(string) string
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Keys(ctx context.Context, prefix string) ([]string, error)
 }
 
-type repository struct {
-	redisClient *redis.Client
+// Config selects the storage backend and carries the settings each backend needs.
+type Config struct {
+	Storage      string
+	RedisAddress string
+	BoltPath     string
 }
 
-func NewRepository(redisAddress string) (Repository, error) {
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: redisAddress,
-	})
-
-	status := redisClient.Ping()
-	err := status.Err()
-	if err != nil {
-		return nil, err
+// New builds the repository for the configured storage backend.
+func New(config Config) (Repository, error) {
+	switch config.Storage {
+	case StorageRedis, "":
+		return NewRedisRepository(config.RedisAddress)
+	case StorageMemory:
+		return NewMemoryRepository(), nil
+	case StorageBolt:
+		return NewBoltRepository(config.BoltPath)
+	default:
+		return nil, fmt.Errorf("unknown storage %q, must be one of %s, %s or %s", config.Storage, StorageRedis, StorageMemory, StorageBolt)
 	}
-
-	return repository{
-		redisClient: redisClient,
-	}, nil
-}
-
-func (r repository) Set(key string, value string) {
-	_ = r.redisClient.Set(key, value, 0)
-}
-
-func (r repository) Get(key string) string {
-	value, _ := r.redisClient.Get(key).Result()
-	return value
 }
@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"chart-viewer/pkg/repository"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepository_Memory(t *testing.T) {
+	repo, err := repository.New(repository.Config{Storage: repository.StorageMemory})
+	assert.NoError(t, err)
+
+	testRepository(t, repo)
+}
+
+func TestRepository_Bolt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart-viewer.db")
+	repo, err := repository.New(repository.Config{Storage: repository.StorageBolt, BoltPath: path})
+	assert.NoError(t, err)
+
+	testRepository(t, repo)
+}
+
+func TestRepository_UnknownStorage(t *testing.T) {
+	_, err := repository.New(repository.Config{Storage: "etcd"})
+	assert.Error(t, err)
+}
+
+func testRepository(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	_, found, err := repo.Get(ctx, "repos")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, repo.Set(ctx, "repos", "[{\"name\":\"stable\"}]", 0))
+	value, found, err := repo.Get(ctx, "repos")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "[{\"name\":\"stable\"}]", value)
+
+	assert.NoError(t, repo.Set(ctx, "value-stable-app-v1", "{}", 0))
+	assert.NoError(t, repo.Set(ctx, "value-stable-app-v2", "", 0))
+	assert.NoError(t, repo.Set(ctx, "template-stable-app-v1", "[]", 0))
+	keys, err := repo.Keys(ctx, "value-")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"value-stable-app-v1", "value-stable-app-v2"}, keys)
+
+	value, found, err = repo.Get(ctx, "value-stable-app-v2")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "", value)
+
+	assert.NoError(t, repo.Delete(ctx, "value-stable-app-v1"))
+	exists, err := repo.Exists(ctx, "value-stable-app-v1")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, repo.Set(ctx, "short-lived", "value", 10*time.Millisecond))
+	exists, err = repo.Exists(ctx, "short-lived")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	time.Sleep(20 * time.Millisecond)
+	_, found, err = repo.Get(ctx, "short-lived")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	keys, err = repo.Keys(ctx, "short")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -1,14 +1,17 @@
 package handler
 
 import (
+	"bytes"
 	"chart-viewer/pkg/model"
 	"chart-viewer/pkg/server/service"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -24,17 +27,170 @@ func NewHandler(service service.Service) handler {
 }
 
 func (h *handler) GetReposHandler(w http.ResponseWriter, r *http.Request) {
-	chartRepo := h.service.GetRepos()
-	respondWithJSON(w, http.StatusOK, chartRepo)
+	chartRepo, err := h.service.GetRepos(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Cannot get repos: "+err.Error())
+		return
+	}
+
+	redactedRepos := make([]model.Repo, 0, len(chartRepo))
+	for _, repo := range chartRepo {
+		redactedRepos = append(redactedRepos, repo.Redacted())
+	}
+
+	respondWithJSON(w, http.StatusOK, redactedRepos)
+}
+
+func (h *handler) GetRepoHandler(w http.ResponseWriter, r *http.Request) {
+	repoName := mux.Vars(r)["repo-name"]
+	repo, err := h.service.GetRepo(r.Context(), repoName)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot get repo: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, repo.Redacted())
+}
+
+func (h *handler) AddRepoHandler(w http.ResponseWriter, r *http.Request) {
+	var repo model.Repo
+	err := json.NewDecoder(r.Body).Decode(&repo)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	err = h.service.AddRepo(r.Context(), repo)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot add repo: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, repo.Redacted())
+}
+
+func (h *handler) UpdateRepoHandler(w http.ResponseWriter, r *http.Request) {
+	var repo model.Repo
+	err := json.NewDecoder(r.Body).Decode(&repo)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	repoName := mux.Vars(r)["repo-name"]
+	if repo.Name != "" && repo.Name != repoName {
+		respondWithError(w, http.StatusBadRequest, "Repo name in the body does not match the url, repos cannot be renamed")
+		return
+	}
+	repo.Name = repoName
+
+	err = h.service.UpdateRepo(r.Context(), repo)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot update repo: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, repo.Redacted())
+}
+
+func (h *handler) DeleteRepoHandler(w http.ResponseWriter, r *http.Request) {
+	repoName := mux.Vars(r)["repo-name"]
+	err := h.service.DeleteRepo(r.Context(), repoName)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot delete repo: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetKubeVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	kubeVersions, err := h.service.GetKubeVersions(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Cannot get kube versions: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, kubeVersions)
+}
+
+// GetKubeVersionHandler resolves a release version like 1.21.3 to its minor version, unknown versions are not found.
+func (h *handler) GetKubeVersionHandler(w http.ResponseWriter, r *http.Request) {
+	kubeVersion, err := h.service.GetKubeVersion(r.Context(), mux.Vars(r)["kube-version"])
+	var unknownKubeVersion *service.UnknownKubeVersionError
+	if errors.As(err, &unknownKubeVersion) {
+		respondWithJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error":     "Cannot get kube version: " + err.Error(),
+			"supported": unknownKubeVersion.Supported,
+		})
+		return
+	}
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot get kube version: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, kubeVersion)
+}
+
+func (h *handler) AddKubeVersionHandler(w http.ResponseWriter, r *http.Request) {
+	var kubeVersion model.KubernetesAPIVersion
+	err := json.NewDecoder(r.Body).Decode(&kubeVersion)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	err = h.service.AddKubeVersion(r.Context(), kubeVersion)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot add kube version: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, kubeVersion)
+}
+
+func (h *handler) UpdateKubeVersionHandler(w http.ResponseWriter, r *http.Request) {
+	var kubeVersion model.KubernetesAPIVersion
+	err := json.NewDecoder(r.Body).Decode(&kubeVersion)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	name := mux.Vars(r)["kube-version"]
+	if kubeVersion.KubeVersion != "" && kubeVersion.KubeVersion != name {
+		respondWithError(w, http.StatusBadRequest, "Kube version in the body does not match the url, kube versions cannot be renamed")
+		return
+	}
+	kubeVersion.KubeVersion = name
+
+	err = h.service.UpdateKubeVersion(r.Context(), kubeVersion)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot update kube version: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, kubeVersion)
+}
+
+func (h *handler) DeleteKubeVersionHandler(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteKubeVersion(r.Context(), mux.Vars(r)["kube-version"])
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot delete kube version: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *handler) GetChartsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	repoName := vars["repo-name"]
-	err, charts := h.service.GetCharts(repoName)
+	err, charts := h.service.GetCharts(r.Context(), repoName)
 	if err != nil {
 		errMessage := fmt.Sprintf("Cannot get charts from repos %s: %s", repoName, err.Error())
-		respondWithError(w, http.StatusInternalServerError, errMessage)
+		respondWithError(w, errorStatusCode(err), errMessage)
 		return
 	}
 
@@ -48,23 +204,31 @@ func (h *handler) GetChartHandler(w http.ResponseWriter, r *http.Request) {
 	chartVersion := vars["chart-version"]
 	kubeVersion := r.URL.Query().Get("kube-version")
 
-	err, chart := h.service.GetChart(repoName, chartName, chartVersion)
+	err, chart := h.service.GetChart(r.Context(), repoName, chartName, chartVersion)
 	if err != nil {
 		errMessage := fmt.Sprintf("Cannot get chart %s/%s:%s : %s", repoName, chartName, chartVersion, err.Error())
-		respondWithError(w, http.StatusInternalServerError, errMessage)
+		respondWithError(w, errorStatusCode(err), errMessage)
 		return
 	}
 
-	analyticsResults, err := h.service.AnalyzeTemplate(chart.Templates, kubeVersion)
+	analyticsResults, err := h.service.AnalyzeTemplate(r.Context(), chart.Templates, kubeVersion)
 	if err != nil {
 		log.Printf("error while analyzing the template: %s\n", err)
-		respondWithError(w, 500, err.Error())
+		respondWithRenderError(w, "Cannot analyze templates: ", err)
+		return
+	}
+
+	subchartResults, err := h.service.AnalyzeSubcharts(r.Context(), chart.Dependencies, kubeVersion)
+	if err != nil {
+		log.Printf("error while analyzing the subchart templates: %s\n", err)
+		respondWithRenderError(w, "Cannot analyze subchart templates: ", err)
 		return
 	}
 
 	response := model.AnalyticResponse{
-		Values:    chart.Values,
-		Templates: analyticsResults,
+		Values:       chart.Values,
+		Templates:    append(analyticsResults, subchartResults...),
+		Dependencies: chart.Dependencies,
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
@@ -76,10 +240,10 @@ func (h *handler) GetValuesHandler(w http.ResponseWriter, r *http.Request) {
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
 
-	err, values := h.service.GetValues(repoName, chartName, chartVersion)
+	err, values := h.service.GetValues(r.Context(), repoName, chartName, chartVersion)
 	if err != nil {
 		errMessage := fmt.Sprintf("Cannot get values of %s/%s:%s : %s", repoName, chartName, chartVersion, err.Error())
-		respondWithError(w, http.StatusInternalServerError, errMessage)
+		respondWithError(w, errorStatusCode(err), errMessage)
 		return
 	}
 
@@ -91,9 +255,10 @@ func (h *handler) GetTemplatesHandler(w http.ResponseWriter, r *http.Request) {
 	repoName := vars["repo-name"]
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
-	templates, err := h.service.GetTemplates(repoName, chartName, chartVersion)
+	templates, err := h.service.GetTemplates(r.Context(), repoName, chartName, chartVersion)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error getting templates: "+err.Error())
+		respondWithError(w, errorStatusCode(err), "Error getting templates: "+err.Error())
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, templates)
@@ -106,46 +271,416 @@ func (h *handler) GetManifestsHandler(w http.ResponseWriter, r *http.Request) {
 	chartVersion := vars["chart-version"]
 	hash := vars["hash"]
 
-	manifest := h.service.GetStringifiedManifests(repoName, chartName, chartVersion, hash)
+	manifest, err := h.service.GetStringifiedManifests(r.Context(), repoName, chartName, chartVersion, hash)
+	if errors.Is(err, service.ErrNotFound) {
+		respondWithError(w, http.StatusNotFound, "Manifest not found, render it first")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error getting manifest: "+err.Error())
+		return
+	}
 
 	respondWithText(w, http.StatusOK, manifest)
 }
 
+// RenderManifestsHandler renders the chart with the values documents of the request body, merged in order,
+// and the set, setString and setFile overrides applied on top like helm template does.
 func (h *handler) RenderManifestsHandler(w http.ResponseWriter, r *http.Request) {
-	decoder := json.NewDecoder(r.Body)
-	type renderRequest struct {
-		Values string `json:"values"`
+	var req model.RenderOptions
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
 	}
 
-	var req renderRequest
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
 
-	err := decoder.Decode(&req)
+	err, manifests := h.service.RenderManifest(r.Context(), repoName, chartName, chartVersion, req)
 	if err != nil {
-		panic(err)
+		respondWithRenderError(w, "Error rendering manifest: ", err)
+		return
 	}
 
+	respondWithJSON(w, http.StatusOK, manifests)
+}
+
+func (h *handler) GetChartMetadataHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	values := req.Values
 	repoName := vars["repo-name"]
 	chartName := vars["chart-name"]
 	chartVersion := vars["chart-version"]
 
-	valueBytes := []byte(values)
-	fileLocation := fmt.Sprintf("/tmp/%s-values.yaml", time.Now().Format("20060102150405"))
-	err = ioutil.WriteFile(fileLocation, valueBytes, 0644)
+	metadata, err := h.service.GetChartMetadata(r.Context(), repoName, chartName, chartVersion)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Cannot store values to file: "+err.Error())
+		respondWithError(w, errorStatusCode(err), "Error getting chart metadata: "+err.Error())
 		return
 	}
 
-	valueFile := []string{fileLocation}
-	err, manifests := h.service.RenderManifest(repoName, chartName, chartVersion, valueFile)
+	respondWithJSON(w, http.StatusOK, metadata)
+}
+
+func (h *handler) GetValuesSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	schema, err := h.service.GetValuesSchema(r.Context(), repoName, chartName, chartVersion)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Error rendering manifest: "+err.Error())
+		respondWithError(w, errorStatusCode(err), "Error getting values schema: "+err.Error())
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, manifests)
+	respondWithJSON(w, http.StatusOK, schema)
+}
+
+func (h *handler) ValidateValuesHandler(w http.ResponseWriter, r *http.Request) {
+	var req model.ValuesOptions
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	validation, err := h.service.ValidateValues(r.Context(), repoName, chartName, chartVersion, req)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Error validating values: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, validation)
+}
+
+func (h *handler) DiffTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := r.URL.Query().Get("from")
+	toVersion := r.URL.Query().Get("to")
+
+	if fromVersion == "" || toVersion == "" {
+		respondWithError(w, http.StatusBadRequest, "Both the from and to query parameters are required")
+		return
+	}
+
+	templateDiff, err := h.service.DiffTemplates(r.Context(), repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Error comparing templates: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, templateDiff)
+}
+
+func (h *handler) DiffValuesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := r.URL.Query().Get("from")
+	toVersion := r.URL.Query().Get("to")
+
+	if fromVersion == "" || toVersion == "" {
+		respondWithError(w, http.StatusBadRequest, "Both the from and to query parameters are required")
+		return
+	}
+
+	valuesDiff, err := h.service.DiffValues(r.Context(), repoName, chartName, fromVersion, toVersion)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Error comparing values: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, valuesDiff)
+}
+
+// DiffManifestsHandler renders both versions and compares their resources.
+func (h *handler) DiffManifestsHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := r.URL.Query().Get("from")
+	toVersion := r.URL.Query().Get("to")
+
+	if fromVersion == "" || toVersion == "" {
+		respondWithError(w, http.StatusBadRequest, "Both the from and to query parameters are required")
+		return
+	}
+
+	manifestDiff, err := h.service.DiffManifests(r.Context(), repoName, chartName, fromVersion, toVersion, req)
+	if err != nil {
+		respondWithRenderError(w, "Error comparing manifests: ", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, manifestDiff)
+}
+
+// AnalyzeManifestsHandler renders the chart and analyzes the rendered resources against the kube-version query,
+// capabilities=true renders for that kube version.
+func (h *handler) AnalyzeManifestsHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+	kubeVersion := r.URL.Query().Get("kube-version")
+
+	if kubeVersion == "" {
+		respondWithError(w, http.StatusBadRequest, "The kube-version query parameter is required")
+		return
+	}
+
+	asCapabilities, err := parseBoolQuery(r, "capabilities")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid query parameter: "+err.Error())
+		return
+	}
+
+	analysis, err := h.service.AnalyzeManifests(r.Context(), repoName, chartName, chartVersion, req, kubeVersion, asCapabilities)
+	if err != nil {
+		respondWithRenderError(w, "Error analyzing manifests: ", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, analysis)
+}
+
+// CheckPoliciesHandler renders the chart and reports the rendered resources violating a policy rule.
+func (h *handler) CheckPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	report, err := h.service.CheckPolicies(r.Context(), repoName, chartName, chartVersion, req)
+	if err != nil {
+		respondWithRenderError(w, "Error checking policies: ", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+var imageCSVHeader = []string{"image", "registry", "repository", "tag", "digest", "manifest", "apiVersion", "kind", "namespace", "name", "container", "containerType"}
+
+// GetImagesHandler renders the chart and lists the images of the rendered workloads, as CSV with format=csv.
+func (h *handler) GetImagesHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		respondWithError(w, http.StatusBadRequest, "The format query parameter must be json or csv")
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	inventory, err := h.service.GetImages(r.Context(), repoName, chartName, chartVersion, req)
+	if err != nil {
+		respondWithRenderError(w, "Error listing images: ", err)
+		return
+	}
+
+	if format != "csv" {
+		respondWithJSON(w, http.StatusOK, inventory)
+		return
+	}
+
+	var content bytes.Buffer
+	writer := csv.NewWriter(&content)
+	writer.Write(imageCSVHeader)
+	for _, image := range inventory.Images {
+		writer.Write([]string{
+			image.Image, image.Registry, image.Repository, image.Tag, image.Digest, image.Manifest,
+			image.Resource.APIVersion, image.Resource.Kind, image.Resource.Namespace, image.Resource.Name,
+			image.Container, image.ContainerType,
+		})
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error writing images: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s-images.csv", chartName, chartVersion)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content.Bytes())
+}
+
+// GetRBACReportHandler renders the chart and returns the permission matrix of its roles and bindings.
+func (h *handler) GetRBACReportHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	chartVersion := vars["chart-version"]
+
+	report, err := h.service.GetRBACReport(r.Context(), repoName, chartName, chartVersion, req)
+	if err != nil {
+		respondWithRenderError(w, "Error analyzing RBAC: ", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// DiffRBACHandler renders both versions and compares their permission matrices.
+func (h *handler) DiffRBACHandler(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRenderOptions(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	repoName := vars["repo-name"]
+	chartName := vars["chart-name"]
+	fromVersion := r.URL.Query().Get("from")
+	toVersion := r.URL.Query().Get("to")
+
+	if fromVersion == "" || toVersion == "" {
+		respondWithError(w, http.StatusBadRequest, "Both the from and to query parameters are required")
+		return
+	}
+
+	rbacDiff, err := h.service.DiffRBAC(r.Context(), repoName, chartName, fromVersion, toVersion, req)
+	if err != nil {
+		respondWithRenderError(w, "Error comparing RBAC: ", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rbacDiff)
+}
+
+func (h *handler) GetPolicyRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.GetPolicyRules(r.Context())
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Error getting policy rules: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+// ReloadPolicyRulesHandler reads the user-defined policy rules again, the rules in use are kept when a file is invalid.
+func (h *handler) ReloadPolicyRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ReloadPolicyRules(r.Context())
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Error reloading policy rules: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+const maxChartArchiveSize = 20 << 20
+
+// UploadChartHandler accepts a packaged chart either as the raw request body or as the chart field of a multipart form.
+func (h *handler) UploadChartHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxChartArchiveSize)
+
+	var (
+		archive []byte
+		err     error
+	)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, formErr := r.FormFile("chart")
+		if formErr != nil && isBodyTooLarge(formErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Chart archive is larger than %d MiB", maxChartArchiveSize>>20))
+			return
+		}
+		if formErr != nil {
+			respondWithError(w, http.StatusBadRequest, "Missing chart file in form: "+formErr.Error())
+			return
+		}
+		defer file.Close()
+
+		archive, err = ioutil.ReadAll(file)
+	} else {
+		archive, err = ioutil.ReadAll(r.Body)
+	}
+
+	if err != nil && isBodyTooLarge(err) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Chart archive is larger than %d MiB", maxChartArchiveSize>>20))
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Cannot read chart archive: "+err.Error())
+		return
+	}
+
+	uploadedChart, err := h.service.UploadChart(r.Context(), archive)
+	if err != nil {
+		respondWithError(w, errorStatusCode(err), "Cannot upload chart: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, uploadedChart)
+}
+
+// isBodyTooLarge reports whether reading the body stopped at the limit of http.MaxBytesReader, its error has no type
+// before Go 1.19.
+func isBodyTooLarge(err error) bool {
+	return strings.Contains(err.Error(), "http: request body too large")
+}
+
+func (h *handler) PurgeCacheHandler(w http.ResponseWriter, r *http.Request) {
+	repoName := r.URL.Query().Get("repo")
+	chartName := r.URL.Query().Get("chart")
+
+	if repoName == "" && chartName != "" {
+		respondWithError(w, http.StatusBadRequest, "Purging a chart requires the repo query parameter")
+		return
+	}
+
+	deleted, err := h.service.PurgeCache(r.Context(), repoName, chartName)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Error purging cache: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]int{"deleted": deleted})
 }
 
 func (h *handler) CORS(next http.Handler) http.Handler {
@@ -4,24 +4,28 @@ import (
 	"bytes"
 	"chart-viewer/pkg/server/handler"
 	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/server/service"
 	"chart-viewer/pkg/server/service/mocks"
+	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/kinbiko/jsonassert"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 )
 
 func TestHandler_GetRepos(t *testing.T) {
 	repos := []model.Repo{
 		{Name: "stable", URL: "https://repo.stable"},
+		{Name: "private", URL: "https://repo.private", Username: "admin", Password: "secret", Token: "token", CABundle: "ca", KeyData: "key"},
 	}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetRepos").Return(repos).Once()
+	serviceMock.On("GetRepos", mock.Anything).Return(repos, nil).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/repos", nil)
@@ -38,17 +42,71 @@ func TestHandler_GetRepos(t *testing.T) {
 
 	ja := jsonassert.New(t)
 	ja.Assertf(string(content), `[
-		{"name": "stable","url": "https://repo.stable"}
+		{"name": "stable","url": "https://repo.stable"},
+		{"name": "private","url": "https://repo.private","ca_bundle": "ca"}
 	]`)
 }
 
+func TestHandler_AddRepoHandler(t *testing.T) {
+	repo := model.Repo{Name: "internal", URL: "https://charts.internal"}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("AddRepo", mock.Anything, repo).Return(nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	requestBody := []byte(`{"name": "internal", "url": "https://charts.internal"}`)
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h := http.HandlerFunc(appHandler.AddRepoHandler)
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{"name": "internal", "url": "https://charts.internal"}`)
+}
+
+func TestHandler_AddRepoHandlerConflict(t *testing.T) {
+	repo := model.Repo{Name: "stable", URL: "https://charts.helm.sh/stable"}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("AddRepo", mock.Anything, repo).Return(fmt.Errorf("repo stable: %w", service.ErrRepoExists)).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	requestBody := []byte(`{"name": "stable", "url": "https://charts.helm.sh/stable"}`)
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h := http.HandlerFunc(appHandler.AddRepoHandler)
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestHandler_DeleteRepoHandler(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DeleteRepo", mock.Anything, "internal").Return(nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("DELETE", "/repos/internal", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/repos/{repo-name}", appHandler.DeleteRepoHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
 func TestHandler_GetChartsHandler(t *testing.T) {
 	charts := []model.Chart{
 		{Name: "app-deployment", Versions: []string{"v0.0.1", "v0.0.2"}},
 		{Name: "job-deployment", Versions: []string{"v0.2.0", "v0.2.1"}},
 	}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetCharts", "stable").Return(nil, charts).Once()
+	serviceMock.On("GetCharts", mock.Anything, "stable").Return(nil, charts).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/charts/stable", nil)
@@ -81,8 +139,30 @@ func TestHandler_GetChartHandler(t *testing.T) {
 			},
 		},
 	}
+	chart.Dependencies = []model.Subchart{
+		{
+			Name:        "redis",
+			Version:     "10.5.7",
+			Condition:   "redis.enabled",
+			ValuesScope: "redis",
+			Templates: []model.Template{
+				{
+					Name:    "templates/ingress.yaml",
+					Content: "apiVersion: extensions/v1beta1",
+				},
+			},
+		},
+	}
+	analyticsResults := []model.AnalyticsResult{
+		{Template: chart.Templates[0], Compatible: true},
+	}
+	subchartResults := []model.AnalyticsResult{
+		{Template: chart.Dependencies[0].Templates[0], Compatible: false, Subchart: "redis"},
+	}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetChart", "repo-name", "chart-name", "chart-version").Return(nil, chart).Once()
+	serviceMock.On("GetChart", mock.Anything, "repo-name", "chart-name", "chart-version").Return(nil, chart).Once()
+	serviceMock.On("AnalyzeTemplate", mock.Anything, chart.Templates, "").Return(analyticsResults, nil).Once()
+	serviceMock.On("AnalyzeSubcharts", mock.Anything, chart.Dependencies, "").Return(subchartResults, nil).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/charts/repo-name/chart-name/chart-version", nil)
@@ -106,7 +186,28 @@ func TestHandler_GetChartHandler(t *testing.T) {
 	   "templates":[
 		  {
 			 "name":"deployment.yaml",
-			 "content":"kind: Deployment"
+			 "content":"kind: Deployment",
+			 "compatible":true
+		  },
+		  {
+			 "name":"templates/ingress.yaml",
+			 "content":"apiVersion: extensions/v1beta1",
+			 "compatible":false,
+			 "subchart":"redis"
+		  }
+	   ],
+	   "dependencies":[
+		  {
+			 "name":"redis",
+			 "version":"10.5.7",
+			 "condition":"redis.enabled",
+			 "valuesScope":"redis",
+			 "templates":[
+				{
+				   "name":"templates/ingress.yaml",
+				   "content":"apiVersion: extensions/v1beta1"
+				}
+			 ]
 		  }
 	   ]
 	}`)
@@ -121,7 +222,7 @@ func TestHandler_GetValuesHandler(t *testing.T) {
 		},
 	}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetValues", "repo-name", "chart-name", "chart-version").Return(nil, values).Once()
+	serviceMock.On("GetValues", mock.Anything, "repo-name", "chart-name", "chart-version").Return(nil, values).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/charts/values/repo-name/chart-name/chart-version", nil)
@@ -149,13 +250,35 @@ func TestHandler_GetValuesHandler(t *testing.T) {
 	`)
 }
 
+func TestHandler_GetValuesHandlerUnknownRepo(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("GetValues", mock.Anything, "unknown", "chart-name", "chart-version").Return(fmt.Errorf("repo unknown: %w", service.ErrNotFound), nil).Once()
+	serviceMock.On("GetTemplates", mock.Anything, "unknown", "chart-name", "chart-version").Return(nil, fmt.Errorf("repo unknown: %w", service.ErrNotFound)).Once()
+	serviceMock.On("GetChart", mock.Anything, "unknown", "chart-name", "chart-version").Return(fmt.Errorf("repo unknown: %w", service.ErrNotFound), model.ChartDetail{}).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/values/{repo-name}/{chart-name}/{chart-version}", appHandler.GetValuesHandler)
+	router.HandleFunc("/charts/templates/{repo-name}/{chart-name}/{chart-version}", appHandler.GetTemplatesHandler)
+	router.HandleFunc("/charts/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartHandler)
+
+	for _, path := range []string{"/charts/values", "/charts/templates", "/charts"} {
+		req, err := http.NewRequest("GET", path+"/unknown/chart-name/chart-version", nil)
+		assert.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusNotFound, recorder.Code, path)
+	}
+}
+
 func TestHandler_GetTemplateHandler(t *testing.T) {
 	templates := []model.Template{
 		{Name: "deployment.yaml", Content: "apiVersion: app/Deployment"},
 		{Name: "service.yaml", Content: "kind: Service"},
 	}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetTemplates", "repo-name", "chart-name", "chart-version").Return(templates).Once()
+	serviceMock.On("GetTemplates", mock.Anything, "repo-name", "chart-name", "chart-version").Return(templates, nil).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/charts/templates/repo-name/chart-name/chart-version", nil)
@@ -199,7 +322,7 @@ data:
     include  "/opt/bitnami/nginx/conf/server_blocks/common/*.conf";
 `
 	serviceMock := new(mocks.Service)
-	serviceMock.On("GetStringifiedManifests", "repo-name", "chart-name", "chart-version", "hash").Return(stringfiedManifests).Once()
+	serviceMock.On("GetStringifiedManifests", mock.Anything, "repo-name", "chart-name", "chart-version", "hash").Return(stringfiedManifests, nil).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	req, err := http.NewRequest("GET", "/charts/manifests/repo-name/chart-name/chart-version/hash", nil)
@@ -226,9 +349,9 @@ func TestHandler_RenderManifestsHandler(t *testing.T) {
 			{Name: "service.yaml", Content: "kind: Service"},
 		},
 	}
-	fileLocation := fmt.Sprintf("/tmp/%s-values.yaml", time.Now().Format("20060102150405"))
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"affinity:{}"}}}
 	serviceMock := new(mocks.Service)
-	serviceMock.On("RenderManifest", "repo-name", "chart-name", "chart-version", []string{fileLocation}).Return(nil, manifests).Once()
+	serviceMock.On("RenderManifest", mock.Anything, "repo-name", "chart-name", "chart-version", renderOptions).Return(nil, manifests).Once()
 	appHandler := handler.NewHandler(serviceMock)
 
 	requestBody := []byte(`{"values": "affinity:{}"}`)
@@ -256,3 +379,830 @@ func TestHandler_RenderManifestsHandler(t *testing.T) {
 		}
 	`)
 }
+
+func TestHandler_PurgeCacheHandler(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("PurgeCache", mock.Anything, "stable", "nginx").Return(4, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("DELETE", "/admin/cache?repo=stable&chart=nginx", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h := http.HandlerFunc(appHandler.PurgeCacheHandler)
+	h.ServeHTTP(recorder, req)
+
+	content, err := ioutil.ReadAll(recorder.Body)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(string(content), `{"deleted": 4}`)
+}
+
+func TestHandler_ReloadPolicyRulesHandler(t *testing.T) {
+	rules := []model.PolicyRule{
+		{Name: "team-label", Severity: model.SeverityMedium, Expression: "has(object.metadata.labels.team)", Source: "conventions.yaml"},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("ReloadPolicyRules", mock.Anything).Return(rules, nil).Once()
+	serviceMock.On("ReloadPolicyRules", mock.Anything).Return(nil, fmt.Errorf("%w: parsing broken.yaml", service.ErrInvalidPolicyRule)).Once()
+	appHandler := handler.NewHandler(serviceMock)
+	h := http.HandlerFunc(appHandler.ReloadPolicyRulesHandler)
+
+	req, err := http.NewRequest("POST", "/admin/policies/reload", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `[{"name": "team-label", "severity": "medium", "expression": "has(object.metadata.labels.team)", "source": "conventions.yaml"}]`)
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	ja.Assertf(recorder.Body.String(), `{"error": "Error reloading policy rules: invalid policy rule: parsing broken.yaml"}`)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_UploadChartHandler(t *testing.T) {
+	archive := []byte("chart archive")
+	uploadedChart := model.UploadedChart{ID: "2c26b46b", Repo: "uploads", Name: "sample", Version: "0.1.0"}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("UploadChart", mock.Anything, archive).Return(uploadedChart, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/upload", bytes.NewBuffer(archive))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/gzip")
+
+	recorder := httptest.NewRecorder()
+	h := http.HandlerFunc(appHandler.UploadChartHandler)
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{"id": "2c26b46b", "repo": "uploads", "name": "sample", "version": "0.1.0"}`)
+}
+
+func TestHandler_UploadInvalidChartHandler(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("UploadChart", mock.Anything, mock.Anything).Return(model.UploadedChart{}, fmt.Errorf("%w: no Chart.yaml", service.ErrInvalidChart)).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/upload", bytes.NewBufferString("garbage"))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h := http.HandlerFunc(appHandler.UploadChartHandler)
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestHandler_UploadChartHandlerTooLarge(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	appHandler := handler.NewHandler(serviceMock)
+	h := http.HandlerFunc(appHandler.UploadChartHandler)
+	archive := bytes.Repeat([]byte("a"), 20<<20+1)
+
+	req, err := http.NewRequest("POST", "/charts/upload", bytes.NewReader(archive))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("chart", "sample-0.1.0.tgz")
+	assert.NoError(t, err)
+	part.Write(archive)
+	writer.Close()
+
+	req, err = http.NewRequest("POST", "/charts/upload", &form)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	serviceMock.AssertNotCalled(t, "UploadChart", mock.Anything, mock.Anything)
+}
+
+func TestHandler_RenderManifestsHandlerWithLayersAndOverrides(t *testing.T) {
+	manifests := model.ManifestResponse{
+		URL: "/charts/manifests/repo-name/chart-name/chart-version/hash",
+	}
+	renderOptions := model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{
+			Values:    model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"},
+			Set:       []string{"image.tag=1.17"},
+			SetString: []string{"podAnnotations.version=2"},
+			SetFile:   map[string]string{"config": "server {}"},
+		},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("RenderManifest", mock.Anything, "repo-name", "chart-name", "chart-version", renderOptions).Return(nil, manifests).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	requestBody := []byte(`{
+		"values": ["replicaCount: 1", "replicaCount: 2"],
+		"set": ["image.tag=1.17"],
+		"setString": ["podAnnotations.version=2"],
+		"setFile": {"config": "server {}"}
+	}`)
+	req, err := http.NewRequest("POST", "/charts/manifests/render/repo-name/chart-name/chart-version", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_RenderManifestsHandlerWithRenderOptions(t *testing.T) {
+	manifests := model.ManifestResponse{
+		URL: "/charts/manifests/repo-name/chart-name/chart-version/hash",
+	}
+	renderOptions := model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1"}},
+		ReleaseName:   "my-release",
+		Namespace:     "apps",
+		KubeVersion:   "1.21.3",
+		APIVersions:   []string{"monitoring.coreos.com/v1"},
+		IncludeCRDs:   true,
+		DisableHooks:  true,
+		IsUpgrade:     true,
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("RenderManifest", mock.Anything, "repo-name", "chart-name", "chart-version", renderOptions).Return(nil, manifests).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	requestBody := []byte(`{
+		"values": "replicaCount: 1",
+		"releaseName": "my-release",
+		"namespace": "apps",
+		"kubeVersion": "1.21.3",
+		"apiVersions": ["monitoring.coreos.com/v1"],
+		"includeCRDs": true,
+		"disableHooks": true,
+		"isUpgrade": true
+	}`)
+	req, err := http.NewRequest("POST", "/charts/manifests/render/repo-name/chart-name/chart-version", bytes.NewBuffer(requestBody))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_RenderManifestsHandlerInvalidRenderOptions(t *testing.T) {
+	renderOptions := model.RenderOptions{KubeVersion: "latest"}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("RenderManifest", mock.Anything, "repo-name", "chart-name", "chart-version", renderOptions).
+		Return(fmt.Errorf("%w: kube version \"latest\" is not major.minor[.patch]", service.ErrInvalidRenderOptions), model.ManifestResponse{}).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/manifests/render/repo-name/chart-name/chart-version", bytes.NewBufferString(`{"kubeVersion": "latest"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_DiffTemplatesHandler(t *testing.T) {
+	templateDiff := model.TemplateDiffResponse{
+		From: "0.1.0",
+		To:   "0.2.0",
+		Files: []model.FileDiff{
+			{
+				Name:   "templates/hpa.yaml",
+				Status: model.DiffAdded,
+				Diff:   "--- /dev/null\n+++ 0.2.0/templates/hpa.yaml\n@@ -0,0 +1 @@\n+kind: HorizontalPodAutoscaler\n",
+			},
+		},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DiffTemplates", mock.Anything, "repo-name", "chart-name", "0.1.0", "0.2.0").Return(templateDiff, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/diff/templates/repo-name/chart-name?from=0.1.0&to=0.2.0", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/templates/{repo-name}/{chart-name}", appHandler.DiffTemplatesHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"from": "0.1.0",
+		"to": "0.2.0",
+		"files": [
+			{
+				"name": "templates/hpa.yaml",
+				"status": "added",
+				"diff": "--- /dev/null\n+++ 0.2.0/templates/hpa.yaml\n@@ -0,0 +1 @@\n+kind: HorizontalPodAutoscaler\n"
+			}
+		]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_DiffTemplatesHandlerMissingVersion(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/diff/templates/repo-name/chart-name?from=0.1.0", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/templates/{repo-name}/{chart-name}", appHandler.DiffTemplatesHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	serviceMock.AssertNotCalled(t, "DiffTemplates", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_DiffManifestsHandler(t *testing.T) {
+	manifestDiff := model.ManifestDiffResponse{
+		From: "0.1.0",
+		To:   "0.2.0",
+		Resources: []model.ResourceDiff{
+			{
+				Resource: model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Status:   model.DiffModified,
+				Changes:  []model.FieldChange{{Path: "$.spec.replicas", Status: model.DiffChanged, Old: 2, New: 3}},
+			},
+		},
+	}
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 3"}}}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DiffManifests", mock.Anything, "repo-name", "chart-name", "0.1.0", "0.2.0", renderOptions).Return(manifestDiff, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/diff/manifests/repo-name/chart-name?from=0.1.0&to=0.2.0", bytes.NewBufferString(`{"values": "replicaCount: 3"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"from": "0.1.0",
+		"to": "0.2.0",
+		"resources": [
+			{
+				"resource": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "app"},
+				"status": "modified",
+				"changes": [{"path": "$.spec.replicas", "status": "changed", "old": 2, "new": 3}]
+			}
+		]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_AnalyzeManifestsHandler(t *testing.T) {
+	analysis := model.ManifestAnalysisResponse{
+		KubeVersion: "1.22",
+		Resources: []model.ResourceAnalysis{
+			{
+				Manifest:   "templates/ingress.yaml",
+				Resource:   model.ResourceID{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "app"},
+				Compatible: true,
+			},
+		},
+		Deprecations: []model.DeprecationFinding{
+			{
+				Manifest:     "templates/cronjob.yaml",
+				Resource:     model.ResourceID{APIVersion: "batch/v1beta1", Kind: "CronJob", Name: "backup"},
+				Status:       model.APIDeprecated,
+				DeprecatedIn: "1.21",
+				RemovedIn:    "1.25",
+				Replacement:  "batch/v1",
+				Message:      "batch/v1beta1 CronJob deprecated in 1.21, removed in 1.25, use batch/v1",
+			},
+		},
+	}
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"ingress: true"}}}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("AnalyzeManifests", mock.Anything, "repo-name", "chart-name", "0.1.0", renderOptions, "1.22", true).Return(analysis, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/analyze/repo-name/chart-name/0.1.0?kube-version=1.22&capabilities=true", bytes.NewBufferString(`{"values": "ingress: true"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"kubeVersion": "1.22",
+		"resources": [
+			{
+				"manifest": "templates/ingress.yaml",
+				"resource": {"apiVersion": "networking.k8s.io/v1", "kind": "Ingress", "name": "app"},
+				"compatible": true
+			}
+		],
+		"deprecations": [
+			{
+				"manifest": "templates/cronjob.yaml",
+				"resource": {"apiVersion": "batch/v1beta1", "kind": "CronJob", "name": "backup"},
+				"status": "deprecated",
+				"deprecatedIn": "1.21",
+				"removedIn": "1.25",
+				"replacement": "batch/v1",
+				"message": "batch/v1beta1 CronJob deprecated in 1.21, removed in 1.25, use batch/v1"
+			}
+		]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_CheckPoliciesHandler(t *testing.T) {
+	report := model.PolicyCheckResponse{
+		Findings: []model.PolicyFinding{
+			{
+				Rule:     "privileged-container",
+				Severity: model.SeverityHigh,
+				Manifest: "templates/daemonset.yaml",
+				Resource: model.ResourceID{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent"},
+				Path:     "$.spec.template.spec.containers[0].securityContext.privileged",
+				Message:  "container agent runs privileged",
+			},
+		},
+	}
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"privileged: true"}}}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("CheckPolicies", mock.Anything, "repo-name", "chart-name", "0.1.0", renderOptions).Return(report, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/policies/repo-name/chart-name/0.1.0", bytes.NewBufferString(`{"values": "privileged: true"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/policies/{repo-name}/{chart-name}/{chart-version}", appHandler.CheckPoliciesHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"findings": [
+			{
+				"rule": "privileged-container",
+				"severity": "high",
+				"manifest": "templates/daemonset.yaml",
+				"resource": {"apiVersion": "apps/v1", "kind": "DaemonSet", "name": "agent"},
+				"path": "$.spec.template.spec.containers[0].securityContext.privileged",
+				"message": "container agent runs privileged"
+			}
+		]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_GetImagesHandler(t *testing.T) {
+	inventory := model.ImageInventoryResponse{
+		Images: []model.ContainerImage{
+			{
+				ImageReference: model.ImageReference{Image: "nginx:1.25", Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				Manifest:       "templates/deployment.yaml",
+				Resource:       model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "web", Name: "nginx"},
+				Container:      "nginx",
+				ContainerType:  "containers",
+			},
+		},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("GetImages", mock.Anything, "repo-name", "chart-name", "0.1.0", model.RenderOptions{}).Return(inventory, nil).Twice()
+	appHandler := handler.NewHandler(serviceMock)
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/images/{repo-name}/{chart-name}/{chart-version}", appHandler.GetImagesHandler)
+
+	req, err := http.NewRequest("GET", "/charts/images/repo-name/chart-name/0.1.0", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{
+		"images": [
+			{
+				"image": "nginx:1.25",
+				"registry": "docker.io",
+				"repository": "library/nginx",
+				"tag": "1.25",
+				"manifest": "templates/deployment.yaml",
+				"resource": {"apiVersion": "apps/v1", "kind": "Deployment", "namespace": "web", "name": "nginx"},
+				"container": "nginx",
+				"containerType": "containers"
+			}
+		]
+	}`)
+
+	req, err = http.NewRequest("GET", "/charts/images/repo-name/chart-name/0.1.0?format=csv", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "image,registry,repository,tag,digest,manifest,apiVersion,kind,namespace,name,container,containerType\n"+
+		"nginx:1.25,docker.io,library/nginx,1.25,,templates/deployment.yaml,apps/v1,Deployment,web,nginx,nginx,containers\n", recorder.Body.String())
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_GetRBACReportHandler(t *testing.T) {
+	report := model.RBACReport{
+		Permissions: []model.RBACPermission{
+			{Scope: model.RBACClusterScope, APIGroup: "apps", Resource: "*", Verbs: []string{"*"}, Role: "ClusterRole operator", Subjects: []string{"ServiceAccount apps/operator"}, Wildcard: true},
+		},
+		ServiceAccounts: []string{"apps/operator"},
+		Findings: []model.RBACFinding{
+			{Severity: model.SeverityMedium, Role: "ClusterRole operator", Scope: model.RBACClusterScope, Message: "ClusterRole operator grants * on apps/* in the cluster"},
+		},
+	}
+	renderOptions := model.RenderOptions{Namespace: "apps"}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("GetRBACReport", mock.Anything, "repo-name", "chart-name", "0.1.0", renderOptions).Return(report, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/rbac/repo-name/chart-name/0.1.0", bytes.NewBufferString(`{"namespace": "apps"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/rbac/{repo-name}/{chart-name}/{chart-version}", appHandler.GetRBACReportHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{
+		"permissions": [
+			{
+				"scope": "cluster",
+				"apiGroup": "apps",
+				"resource": "*",
+				"verbs": ["*"],
+				"role": "ClusterRole operator",
+				"subjects": ["ServiceAccount apps/operator"],
+				"wildcard": true
+			}
+		],
+		"serviceAccounts": ["apps/operator"],
+		"findings": [
+			{"severity": "medium", "role": "ClusterRole operator", "scope": "cluster", "message": "ClusterRole operator grants * on apps/* in the cluster"}
+		]
+	}`)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_DiffRBACHandler(t *testing.T) {
+	rbacDiff := model.RBACDiffResponse{
+		From: "0.1.0",
+		To:   "0.2.0",
+		Changes: []model.RBACPermissionChange{
+			{
+				Status:     model.DiffModified,
+				Permission: model.RBACPermission{Scope: model.RBACClusterScope, Resource: "pods", Verbs: []string{"get", "watch"}, Role: "ClusterRole operator"},
+				AddedVerbs: []string{"watch"},
+			},
+		},
+		Findings: []model.RBACFinding{},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DiffRBAC", mock.Anything, "repo-name", "chart-name", "0.1.0", "0.2.0", model.RenderOptions{}).Return(rbacDiff, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/rbac/{repo-name}/{chart-name}", appHandler.DiffRBACHandler)
+
+	req, err := http.NewRequest("GET", "/charts/diff/rbac/repo-name/chart-name?from=0.1.0&to=0.2.0", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{
+		"from": "0.1.0",
+		"to": "0.2.0",
+		"changes": [
+			{
+				"status": "modified",
+				"permission": {"scope": "cluster", "apiGroup": "", "resource": "pods", "verbs": ["get", "watch"], "role": "ClusterRole operator"},
+				"addedVerbs": ["watch"]
+			}
+		],
+		"findings": []
+	}`)
+
+	req, err = http.NewRequest("GET", "/charts/diff/rbac/repo-name/chart-name?from=0.1.0", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_AnalyzeManifestsHandlerInvalidQuery(t *testing.T) {
+	appHandler := handler.NewHandler(new(mocks.Service))
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifestsHandler)
+
+	for _, query := range []string{"", "?kube-version=1.22&capabilities=maybe"} {
+		req, err := http.NewRequest("GET", "/charts/analyze/repo-name/chart-name/0.1.0"+query, http.NoBody)
+		assert.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestHandler_DiffManifestsHandlerWithoutBody(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DiffManifests", mock.Anything, "repo-name", "chart-name", "0.1.0", "0.2.0", model.RenderOptions{}).Return(model.ManifestDiffResponse{}, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/diff/manifests/repo-name/chart-name?from=0.1.0&to=0.2.0", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/manifests/{repo-name}/{chart-name}", appHandler.DiffManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_DiffValuesHandler(t *testing.T) {
+	valuesDiff := model.ValuesDiffResponse{
+		From: "0.1.0",
+		To:   "0.2.0",
+		Changes: []model.ValueChange{
+			{Path: "controller.image.tag", OldPath: "image.tag", Status: model.DiffRenamed, Old: "1.16", New: "1.16"},
+			{Path: "replicaCount", Status: model.DiffChanged, Old: 1, New: 2},
+		},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("DiffValues", mock.Anything, "repo-name", "chart-name", "0.1.0", "0.2.0").Return(valuesDiff, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/diff/values/repo-name/chart-name?from=0.1.0&to=0.2.0", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/diff/values/{repo-name}/{chart-name}", appHandler.DiffValuesHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"from": "0.1.0",
+		"to": "0.2.0",
+		"changes": [
+			{"path": "controller.image.tag", "oldPath": "image.tag", "status": "renamed", "old": "1.16", "new": "1.16"},
+			{"path": "replicaCount", "status": "changed", "old": 1, "new": 2}
+		]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_GetValuesSchemaHandler(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	serviceMock.On("GetValuesSchema", mock.Anything, "repo-name", "chart-name", "chart-version").Return(json.RawMessage(`{"type": "object"}`), nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/schema/repo-name/chart-name/chart-version", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/schema/{repo-name}/{chart-name}/{chart-version}", appHandler.GetValuesSchemaHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{"type": "object"}`)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_ValidateValuesHandler(t *testing.T) {
+	validation := model.ValuesValidationResponse{
+		Valid:  false,
+		Errors: []model.SchemaError{{Path: "$.replicaCount", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"}},
+	}
+	valuesOptions := model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: two"}}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("ValidateValues", mock.Anything, "repo-name", "chart-name", "chart-version", valuesOptions).Return(validation, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/schema/validate/repo-name/chart-name/chart-version", bytes.NewBufferString(`{"values": "replicaCount: two"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/schema/validate/{repo-name}/{chart-name}/{chart-version}", appHandler.ValidateValuesHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"valid": false,
+		"errors": [{"path": "$.replicaCount", "type": "invalid_type", "message": "Invalid type. Expected: integer, given: string"}]
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_RenderManifestsHandlerInvalidValues(t *testing.T) {
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: two"}}}
+	invalidValues := &service.InvalidValuesError{
+		Errors: []model.SchemaError{{Path: "$.replicaCount", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"}},
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("RenderManifest", mock.Anything, "repo-name", "chart-name", "chart-version", renderOptions).Return(invalidValues, model.ManifestResponse{}).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("POST", "/charts/manifests/render/repo-name/chart-name/chart-version", bytes.NewBufferString(`{"values": "replicaCount: two"}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/manifests/render/{repo-name}/{chart-name}/{chart-version}", appHandler.RenderManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"error": "Error rendering manifest: values do not match the chart schema: $.replicaCount: Invalid type. Expected: integer, given: string",
+		"errors": [{"path": "$.replicaCount", "type": "invalid_type", "message": "Invalid type. Expected: integer, given: string"}]
+	}`
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_GetChartMetadataHandler(t *testing.T) {
+	metadata := model.ChartMetadata{
+		Name:        "chart-name",
+		Version:     "chart-version",
+		APIVersion:  "v2",
+		Type:        "application",
+		Description: "A chart",
+		AppVersion:  "1.16.0",
+		KubeVersion: ">=1.16.0-0",
+		Keywords:    []string{"web"},
+		Maintainers: []model.ChartMaintainer{{Name: "maintainer", Email: "maintainer@example.com"}},
+		Deprecated:  false,
+		Annotations: map[string]string{"category": "web"},
+		Dependencies: []model.ChartDependency{
+			{Name: "redis", Version: "10.x.x", Repository: "https://charts.example.com", Condition: "redis.enabled"},
+		},
+		Digest: "6c1a2b",
+	}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("GetChartMetadata", mock.Anything, "repo-name", "chart-name", "chart-version").Return(metadata, nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/metadata/repo-name/chart-name/chart-version", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/metadata/{repo-name}/{chart-name}/{chart-version}", appHandler.GetChartMetadataHandler)
+	router.ServeHTTP(recorder, req)
+
+	expectedResponse := `{
+		"name": "chart-name",
+		"version": "chart-version",
+		"apiVersion": "v2",
+		"type": "application",
+		"description": "A chart",
+		"appVersion": "1.16.0",
+		"kubeVersion": ">=1.16.0-0",
+		"keywords": ["web"],
+		"maintainers": [{"name": "maintainer", "email": "maintainer@example.com"}],
+		"deprecated": false,
+		"annotations": {"category": "web"},
+		"dependencies": [{"name": "redis", "version": "10.x.x", "repository": "https://charts.example.com", "condition": "redis.enabled"}],
+		"digest": "6c1a2b"
+	}`
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), expectedResponse)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_GetKubeVersionHandlerUnknown(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	unknown := &service.UnknownKubeVersionError{Version: "1.99", Supported: []string{"1.21", "1.22"}}
+	serviceMock.On("GetKubeVersion", mock.Anything, "1.99").Return(model.KubernetesAPIVersion{}, unknown).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/kube-versions/1.99", nil)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/kube-versions/{kube-version}", appHandler.GetKubeVersionHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{
+		"error": "Cannot get kube version: kube version \"1.99\" is not supported, supported versions are 1.21, 1.22",
+		"supported": ["1.21", "1.22"]
+	}`)
+}
+
+func TestHandler_UpdateKubeVersionHandler(t *testing.T) {
+	kubeVersion := model.KubernetesAPIVersion{KubeVersion: "1.22", APIVersions: []string{"apps/v1", "v1"}}
+	serviceMock := new(mocks.Service)
+	serviceMock.On("UpdateKubeVersion", mock.Anything, kubeVersion).Return(nil).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("PUT", "/kube-versions/1.22", bytes.NewBufferString(`{"api_versions": ["apps/v1", "v1"]}`))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/kube-versions/{kube-version}", appHandler.UpdateKubeVersionHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{"kube_version": "1.22", "api_versions": ["apps/v1", "v1"]}`)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestHandler_AnalyzeManifestsHandlerUnknownKubeVersion(t *testing.T) {
+	serviceMock := new(mocks.Service)
+	unknown := &service.UnknownKubeVersionError{Version: "2.0", Supported: []string{"1.21"}}
+	serviceMock.On("AnalyzeManifests", mock.Anything, "repo-name", "chart-name", "0.1.0", model.RenderOptions{}, "2.0", false).
+		Return(model.ManifestAnalysisResponse{}, unknown).Once()
+	appHandler := handler.NewHandler(serviceMock)
+
+	req, err := http.NewRequest("GET", "/charts/analyze/repo-name/chart-name/0.1.0?kube-version=2.0", http.NoBody)
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/charts/analyze/{repo-name}/{chart-name}/{chart-version}", appHandler.AnalyzeManifestsHandler)
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	ja := jsonassert.New(t)
+	ja.Assertf(recorder.Body.String(), `{
+		"error": "Error analyzing manifests: kube version \"2.0\" is not supported, supported versions are 1.21",
+		"supported": ["1.21"]
+	}`)
+}
@@ -1,14 +1,82 @@
 package handler
 
 import (
+	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/server/service"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 )
 
+// errorStatusCode maps the service errors to the HTTP status code they should be reported with.
+func errorStatusCode(err error) int {
+	var (
+		invalidValues      *service.InvalidValuesError
+		unknownKubeVersion *service.UnknownKubeVersionError
+	)
+
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrRepoExists), errors.Is(err, service.ErrKubeVersionExists):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidRepo), errors.Is(err, service.ErrInvalidChart),
+		errors.Is(err, service.ErrInvalidRenderOptions), errors.Is(err, service.ErrInvalidKubeVersion),
+		errors.As(err, &unknownKubeVersion):
+		return http.StatusBadRequest
+	case errors.As(err, &invalidValues), errors.Is(err, service.ErrInvalidPolicyRule):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// decodeRenderOptions reads the render options of the endpoints rendering a chart. Their body is optional, a request
+// without one renders with the default options.
+func decodeRenderOptions(r *http.Request) (model.RenderOptions, error) {
+	var renderOptions model.RenderOptions
+	err := json.NewDecoder(r.Body).Decode(&renderOptions)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return model.RenderOptions{}, err
+	}
+
+	return renderOptions, nil
+}
+
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
 }
 
+// respondWithRenderError adds the schema errors to the response when the values did not match the chart schema,
+// and the supported kube versions when the requested one is unknown.
+func respondWithRenderError(w http.ResponseWriter, message string, err error) {
+	var (
+		invalidValues      *service.InvalidValuesError
+		unknownKubeVersion *service.UnknownKubeVersionError
+	)
+
+	if errors.As(err, &invalidValues) {
+		respondWithJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":  message + err.Error(),
+			"errors": invalidValues.Errors,
+		})
+		return
+	}
+
+	if errors.As(err, &unknownKubeVersion) {
+		respondWithJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":     message + err.Error(),
+			"supported": unknownKubeVersion.Supported,
+		})
+		return
+	}
+
+	respondWithError(w, errorStatusCode(err), message+err.Error())
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 
@@ -22,3 +90,18 @@ func respondWithText(w http.ResponseWriter, code int, payload string) {
 	w.WriteHeader(code)
 	w.Write([]byte(payload))
 }
+
+// parseBoolQuery reads an optional boolean query parameter, a missing parameter is false.
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", name, value)
+	}
+
+	return parsed, nil
+}
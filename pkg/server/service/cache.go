@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Cache keys are colon separated so that a repo or chart prefix never matches a longer name sharing the same start.
+const (
+	chartsCachePrefix       = "charts"
+	valuesCachePrefix       = "value"
+	templatesCachePrefix    = "template"
+	manifestsCachePrefix    = "manifests"
+	schemaCachePrefix       = "schema"
+	metadataCachePrefix     = "metadata"
+	dependenciesCachePrefix = "dependencies"
+)
+
+// legacyCachePrefixes are the dash separated cache families written before the keys were colon separated, the chart
+// list of a repo was cached under the bare repo name. Nothing reads them anymore, they are deleted on startup.
+var legacyCachePrefixes = []string{"value-", "template-", "manifests-"}
+
+// The Kubernetes API data is seeded from api_versions.json and api_deprecations.json, it never expires. The
+// api-versions array is only found in storages seeded before kube versions had their own records.
+const (
+	apiVersionsKey     = "api-versions"
+	apiDeprecationsKey = "api-deprecations"
+)
+
+// chartCachePrefixes are the cache families holding data of a single chart version.
+var chartCachePrefixes = []string{valuesCachePrefix, templatesCachePrefix, manifestsCachePrefix, schemaCachePrefix, metadataCachePrefix, dependenciesCachePrefix}
+
+// CacheTTL is the expiry of each cache family, zero keeps the entries until they are purged.
+type CacheTTL struct {
+	RepoIndex time.Duration
+	Values    time.Duration
+	Templates time.Duration
+	Manifests time.Duration
+}
+
+func cacheKey(parts ...string) string {
+	return strings.Join(parts, ":")
+}
+
+func chartsCacheKey(repoName string) string {
+	return cacheKey(chartsCachePrefix, repoName)
+}
+
+func valuesCacheKey(repoName, chartName, chartVersion string) string {
+	return cacheKey(valuesCachePrefix, repoName, chartName, chartVersion)
+}
+
+func templatesCacheKey(repoName, chartName, chartVersion string) string {
+	return cacheKey(templatesCachePrefix, repoName, chartName, chartVersion)
+}
+
+func schemaCacheKey(repoName, chartName, chartVersion string) string {
+	return cacheKey(schemaCachePrefix, repoName, chartName, chartVersion)
+}
+
+func metadataCacheKey(repoName, chartName, chartVersion string) string {
+	return cacheKey(metadataCachePrefix, repoName, chartName, chartVersion)
+}
+
+func dependenciesCacheKey(repoName, chartName, chartVersion string) string {
+	return cacheKey(dependenciesCachePrefix, repoName, chartName, chartVersion)
+}
+
+func manifestsCacheKey(repoName, chartName, chartVersion, hash string) string {
+	return cacheKey(manifestsCachePrefix, repoName, chartName, chartVersion, hash)
+}
+
+// getCache decodes the JSON cached under key into target and reports whether the key was found.
+func (s *service) getCache(ctx context.Context, key string, target interface{}) (bool, error) {
+	stringified, found, err := s.repository.Get(ctx, key)
+	if err != nil || !found {
+		return false, err
+	}
+
+	err = json.Unmarshal([]byte(stringified), target)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *service) setCache(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	valueByte, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Set(ctx, key, string(valueByte), ttl)
+}
+
+// PurgeCache deletes the cached chart data of a repo, of a single chart when chartName is given,
+// or of every repo when repoName is empty. It returns the number of deleted entries.
+func (s *service) PurgeCache(ctx context.Context, repoName, chartName string) (int, error) {
+	if repoName == "" && chartName != "" {
+		return 0, fmt.Errorf("purging chart %s requires its repo name", chartName)
+	}
+
+	var prefixes []string
+	switch {
+	case repoName == "":
+		prefixes = append(prefixes, cacheKey(chartsCachePrefix, ""))
+		for _, prefix := range chartCachePrefixes {
+			prefixes = append(prefixes, cacheKey(prefix, ""))
+		}
+	case chartName == "":
+		for _, prefix := range chartCachePrefixes {
+			prefixes = append(prefixes, cacheKey(prefix, repoName, ""))
+		}
+	default:
+		for _, prefix := range chartCachePrefixes {
+			prefixes = append(prefixes, cacheKey(prefix, repoName, chartName, ""))
+		}
+	}
+
+	var keys []string
+	for _, prefix := range prefixes {
+		prefixKeys, err := s.repository.Keys(ctx, prefix)
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, prefixKeys...)
+	}
+
+	// the chart list of a repo is a single key, listing it by prefix would match repos sharing the start of its name
+	if repoName != "" && chartName == "" {
+		exists, err := s.repository.Exists(ctx, chartsCacheKey(repoName))
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			keys = append(keys, chartsCacheKey(repoName))
+		}
+	}
+
+	deleted := 0
+	for _, key := range keys {
+		err := s.repository.Delete(ctx, key)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	log.Printf("purged %d cache entries of repo %q chart %q\n", deleted, repoName, chartName)
+	return deleted, nil
+}
+
+// DeleteLegacyCache deletes the cache entries written with the keys used before they were colon separated, they were
+// stored without expiry and would otherwise stay in the storage forever. It returns the number of deleted entries.
+func (s *service) DeleteLegacyCache(ctx context.Context) (int, error) {
+	var keys []string
+	for _, prefix := range legacyCachePrefixes {
+		prefixKeys, err := s.repository.Keys(ctx, prefix)
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, prefixKeys...)
+	}
+
+	repos, err := s.GetRepos(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, repo := range repos {
+		// a repo named like a storage key would have replaced it, the key is not its chart list
+		if repo.Name == legacyReposKey || repo.Name == apiVersionsKey || repo.Name == apiDeprecationsKey {
+			continue
+		}
+
+		exists, err := s.repository.Exists(ctx, repo.Name)
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			keys = append(keys, repo.Name)
+		}
+	}
+
+	deleted := 0
+	for _, key := range keys {
+		err := s.repository.Delete(ctx, key)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	if deleted != 0 {
+		log.Printf("deleted %d legacy cache entries\n", deleted)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"chart-viewer/pkg/kubeversion"
+	"chart-viewer/pkg/model"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const kubeVersionKeyPrefix = "kube-version"
+
+var (
+	ErrKubeVersionExists  = errors.New("kube version already exists")
+	ErrInvalidKubeVersion = errors.New("invalid kube version")
+)
+
+// UnknownKubeVersionError is returned for a kube version that has no API data, Supported lists the known ones.
+type UnknownKubeVersionError struct {
+	Version   string
+	Supported []string
+}
+
+func (e *UnknownKubeVersionError) Error() string {
+	return fmt.Sprintf("kube version %q is not supported, supported versions are %s", e.Version, strings.Join(e.Supported, ", "))
+}
+
+func kubeVersionKey(kubeVersion string) string {
+	return cacheKey(kubeVersionKeyPrefix, kubeVersion)
+}
+
+// GetKubeVersions returns the API data of every kube version, release versions first in version order.
+func (s *service) GetKubeVersions(ctx context.Context) ([]model.KubernetesAPIVersion, error) {
+	keys, err := s.repository.Keys(ctx, kubeVersionKey(""))
+	if err != nil {
+		return nil, err
+	}
+
+	kubeVersions := []model.KubernetesAPIVersion{}
+	if len(keys) == 0 {
+		// Storages seeded before kube versions had their own records hold them as one JSON array until the first write.
+		_, err = s.getCache(ctx, apiVersionsKey, &kubeVersions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range keys {
+		var kubeVersion model.KubernetesAPIVersion
+		found, err := s.getCache(ctx, key, &kubeVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			kubeVersions = append(kubeVersions, kubeVersion)
+		}
+	}
+
+	sort.Slice(kubeVersions, func(i, j int) bool {
+		return kubeVersionLess(kubeVersions[i].KubeVersion, kubeVersions[j].KubeVersion)
+	})
+
+	return kubeVersions, nil
+}
+
+// GetKubeVersion returns the API data of a kube version. Versions are matched by name first, then a release
+// version like v1.21.3 resolves to its minor version 1.21.
+func (s *service) GetKubeVersion(ctx context.Context, kubeVersion string) (model.KubernetesAPIVersion, error) {
+	kubeVersions, err := s.GetKubeVersions(ctx)
+	if err != nil {
+		return model.KubernetesAPIVersion{}, err
+	}
+
+	candidates := []string{kubeVersion}
+	if minor, ok := minorKubeVersion(kubeVersion); ok {
+		candidates = append(candidates, minor)
+	}
+
+	for _, candidate := range candidates {
+		for _, k := range kubeVersions {
+			if k.KubeVersion == candidate {
+				return k, nil
+			}
+		}
+	}
+
+	supported := make([]string, 0, len(kubeVersions))
+	for _, k := range kubeVersions {
+		supported = append(supported, k.KubeVersion)
+	}
+
+	return model.KubernetesAPIVersion{}, &UnknownKubeVersionError{Version: kubeVersion, Supported: supported}
+}
+
+func (s *service) AddKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error {
+	err := validateKubeVersion(kubeVersion)
+	if err != nil {
+		return err
+	}
+
+	err = s.migrateLegacyKubeVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.Exists(ctx, kubeVersionKey(kubeVersion.KubeVersion))
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("kube version %s: %w", kubeVersion.KubeVersion, ErrKubeVersionExists)
+	}
+
+	return s.setCache(ctx, kubeVersionKey(kubeVersion.KubeVersion), kubeVersion, 0)
+}
+
+func (s *service) UpdateKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error {
+	err := validateKubeVersion(kubeVersion)
+	if err != nil {
+		return err
+	}
+
+	err = s.migrateLegacyKubeVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.Exists(ctx, kubeVersionKey(kubeVersion.KubeVersion))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("kube version %s: %w", kubeVersion.KubeVersion, ErrNotFound)
+	}
+
+	return s.setCache(ctx, kubeVersionKey(kubeVersion.KubeVersion), kubeVersion, 0)
+}
+
+func (s *service) DeleteKubeVersion(ctx context.Context, kubeVersion string) error {
+	err := s.migrateLegacyKubeVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.Exists(ctx, kubeVersionKey(kubeVersion))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("kube version %s: %w", kubeVersion, ErrNotFound)
+	}
+
+	return s.repository.Delete(ctx, kubeVersionKey(kubeVersion))
+}
+
+// ImportKubeVersions adds the kube versions, replacing the stored ones with the same name. Nothing is stored when
+// one of them is invalid.
+func (s *service) ImportKubeVersions(ctx context.Context, kubeVersions []model.KubernetesAPIVersion) error {
+	for _, kubeVersion := range kubeVersions {
+		err := validateKubeVersion(kubeVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.migrateLegacyKubeVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, kubeVersion := range kubeVersions {
+		err = s.setCache(ctx, kubeVersionKey(kubeVersion.KubeVersion), kubeVersion, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateLegacyKubeVersions copies the legacy array to per version records and deletes it before the first write,
+// otherwise the versions it holds would no longer be read once a record exists, and would be read again once the
+// last record is deleted. An array left next to the records by an earlier migration is deleted as well.
+func (s *service) migrateLegacyKubeVersions(ctx context.Context) error {
+	var kubeVersions []model.KubernetesAPIVersion
+	found, err := s.getCache(ctx, apiVersionsKey, &kubeVersions)
+	if err != nil || !found {
+		return err
+	}
+
+	keys, err := s.repository.Keys(ctx, kubeVersionKey(""))
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		for _, kubeVersion := range kubeVersions {
+			err = s.setCache(ctx, kubeVersionKey(kubeVersion.KubeVersion), kubeVersion, 0)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.repository.Delete(ctx, apiVersionsKey)
+}
+
+func validateKubeVersion(kubeVersion model.KubernetesAPIVersion) error {
+	if kubeVersion.KubeVersion == "" {
+		return fmt.Errorf("%w: kube_version is required", ErrInvalidKubeVersion)
+	}
+
+	if strings.ContainsAny(kubeVersion.KubeVersion, ":/ ") {
+		return fmt.Errorf("%w: name %q must not contain ':', '/' or spaces", ErrInvalidKubeVersion, kubeVersion.KubeVersion)
+	}
+
+	if _, isRelease := minorKubeVersion(kubeVersion.Release); kubeVersion.Release != "" && !isRelease {
+		return fmt.Errorf("%w: release %q of %s is not major.minor[.patch]", ErrInvalidKubeVersion, kubeVersion.Release, kubeVersion.KubeVersion)
+	}
+
+	if len(kubeVersion.APIVersions) == 0 {
+		return fmt.Errorf("%w: %s has no api_versions", ErrInvalidKubeVersion, kubeVersion.KubeVersion)
+	}
+
+	served := make(map[string]bool, len(kubeVersion.APIVersions))
+	for _, apiVersion := range kubeVersion.APIVersions {
+		if apiVersion == "" {
+			return fmt.Errorf("%w: %s has an empty api version", ErrInvalidKubeVersion, kubeVersion.KubeVersion)
+		}
+		served[apiVersion] = true
+	}
+
+	for apiVersion := range kubeVersion.Kinds {
+		if !served[apiVersion] {
+			return fmt.Errorf("%w: %s lists kinds of %s which is not in its api_versions", ErrInvalidKubeVersion, kubeVersion.KubeVersion, apiVersion)
+		}
+	}
+
+	return nil
+}
+
+// minorKubeVersion resolves 1.21.3, v1.21 or v1.21.3 to 1.21.
+func minorKubeVersion(kubeVersion string) (string, bool) {
+	version, err := kubeversion.Parse(kubeVersion)
+	if err != nil {
+		return "", false
+	}
+
+	return version.MinorRelease(), true
+}
+
+// kubeVersionLess orders release versions numerically and before named versions, which are ordered by name.
+func kubeVersionLess(a, b string) bool {
+	aVersion, aErr := kubeversion.Parse(a)
+	bVersion, bErr := kubeversion.Parse(b)
+	aRelease, bRelease := aErr == nil, bErr == nil
+
+	switch {
+	case aRelease && bRelease && aVersion != bVersion:
+		return aVersion.Before(bVersion)
+	case aRelease != bRelease:
+		return aRelease
+	default:
+		return a < b
+	}
+}
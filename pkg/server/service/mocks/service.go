@@ -4,6 +4,8 @@ package mocks
 
 import (
 	model "chart-viewer/pkg/model"
+	context "context"
+	json "encoding/json"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,20 +15,269 @@ type Service struct {
 	mock.Mock
 }
 
-// GetChart provides a mock function with given fields: repoName, chartName, chartVersion
-func (_m *Service) GetChart(repoName string, chartName string, chartVersion string) (error, model.ChartDetail) {
-	ret := _m.Called(repoName, chartName, chartVersion)
+// AddKubeVersion provides a mock function with given fields: ctx, kubeVersion
+func (_m *Service) AddKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error {
+	ret := _m.Called(ctx, kubeVersion)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(repoName, chartName, chartVersion)
+	if rf, ok := ret.Get(0).(func(context.Context, model.KubernetesAPIVersion) error); ok {
+		r0 = rf(ctx, kubeVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddRepo provides a mock function with given fields: ctx, repo
+func (_m *Service) AddRepo(ctx context.Context, repo model.Repo) error {
+	ret := _m.Called(ctx, repo)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Repo) error); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AnalyzeManifests provides a mock function with given fields: ctx, repoName, chartName, chartVersion, renderOptions, kubeVersion, asCapabilities
+func (_m *Service) AnalyzeManifests(ctx context.Context, repoName string, chartName string, chartVersion string, renderOptions model.RenderOptions, kubeVersion string, asCapabilities bool) (model.ManifestAnalysisResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, renderOptions, kubeVersion, asCapabilities)
+
+	var r0 model.ManifestAnalysisResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RenderOptions, string, bool) model.ManifestAnalysisResponse); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, renderOptions, kubeVersion, asCapabilities)
+	} else {
+		r0 = ret.Get(0).(model.ManifestAnalysisResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.RenderOptions, string, bool) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, renderOptions, kubeVersion, asCapabilities)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AnalyzeSubcharts provides a mock function with given fields: ctx, subcharts, kubeVersion
+func (_m *Service) AnalyzeSubcharts(ctx context.Context, subcharts []model.Subchart, kubeVersion string) ([]model.AnalyticsResult, error) {
+	ret := _m.Called(ctx, subcharts, kubeVersion)
+
+	var r0 []model.AnalyticsResult
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Subchart, string) []model.AnalyticsResult); ok {
+		r0 = rf(ctx, subcharts, kubeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AnalyticsResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []model.Subchart, string) error); ok {
+		r1 = rf(ctx, subcharts, kubeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AnalyzeTemplate provides a mock function with given fields: ctx, templates, kubeVersion
+func (_m *Service) AnalyzeTemplate(ctx context.Context, templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error) {
+	ret := _m.Called(ctx, templates, kubeVersion)
+
+	var r0 []model.AnalyticsResult
+	if rf, ok := ret.Get(0).(func(context.Context, []model.Template, string) []model.AnalyticsResult); ok {
+		r0 = rf(ctx, templates, kubeVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AnalyticsResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []model.Template, string) error); ok {
+		r1 = rf(ctx, templates, kubeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckPolicies provides a mock function with given fields: ctx, repoName, chartName, chartVersion, renderOptions
+func (_m *Service) CheckPolicies(ctx context.Context, repoName string, chartName string, chartVersion string, renderOptions model.RenderOptions) (model.PolicyCheckResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, renderOptions)
+
+	var r0 model.PolicyCheckResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RenderOptions) model.PolicyCheckResponse); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r0 = ret.Get(0).(model.PolicyCheckResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteKubeVersion provides a mock function with given fields: ctx, kubeVersion
+func (_m *Service) DeleteKubeVersion(ctx context.Context, kubeVersion string) error {
+	ret := _m.Called(ctx, kubeVersion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, kubeVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLegacyCache provides a mock function with given fields: ctx
+func (_m *Service) DeleteLegacyCache(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteRepo provides a mock function with given fields: ctx, repoName
+func (_m *Service) DeleteRepo(ctx context.Context, repoName string) error {
+	ret := _m.Called(ctx, repoName)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, repoName)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiffManifests provides a mock function with given fields: ctx, repoName, chartName, fromVersion, toVersion, renderOptions
+func (_m *Service) DiffManifests(ctx context.Context, repoName string, chartName string, fromVersion string, toVersion string, renderOptions model.RenderOptions) (model.ManifestDiffResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+
+	var r0 model.ManifestDiffResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RenderOptions) model.ManifestDiffResponse); ok {
+		r0 = rf(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+	} else {
+		r0 = ret.Get(0).(model.ManifestDiffResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffRBAC provides a mock function with given fields: ctx, repoName, chartName, fromVersion, toVersion, renderOptions
+func (_m *Service) DiffRBAC(ctx context.Context, repoName string, chartName string, fromVersion string, toVersion string, renderOptions model.RenderOptions) (model.RBACDiffResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+
+	var r0 model.RBACDiffResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, model.RenderOptions) model.RBACDiffResponse); ok {
+		r0 = rf(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+	} else {
+		r0 = ret.Get(0).(model.RBACDiffResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, fromVersion, toVersion, renderOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffTemplates provides a mock function with given fields: ctx, repoName, chartName, fromVersion, toVersion
+func (_m *Service) DiffTemplates(ctx context.Context, repoName string, chartName string, fromVersion string, toVersion string) (model.TemplateDiffResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, fromVersion, toVersion)
+
+	var r0 model.TemplateDiffResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) model.TemplateDiffResponse); ok {
+		r0 = rf(ctx, repoName, chartName, fromVersion, toVersion)
+	} else {
+		r0 = ret.Get(0).(model.TemplateDiffResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffValues provides a mock function with given fields: ctx, repoName, chartName, fromVersion, toVersion
+func (_m *Service) DiffValues(ctx context.Context, repoName string, chartName string, fromVersion string, toVersion string) (model.ValuesDiffResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, fromVersion, toVersion)
+
+	var r0 model.ValuesDiffResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) model.ValuesDiffResponse); ok {
+		r0 = rf(ctx, repoName, chartName, fromVersion, toVersion)
+	} else {
+		r0 = ret.Get(0).(model.ValuesDiffResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChart provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetChart(ctx context.Context, repoName string, chartName string, chartVersion string) (error, model.ChartDetail) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 model.ChartDetail
-	if rf, ok := ret.Get(1).(func(string, string, string) model.ChartDetail); ok {
-		r1 = rf(repoName, chartName, chartVersion)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) model.ChartDetail); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
 	} else {
 		r1 = ret.Get(1).(model.ChartDetail)
 	}
@@ -34,20 +285,41 @@ func (_m *Service) GetChart(repoName string, chartName string, chartVersion stri
 	return r0, r1
 }
 
-// GetCharts provides a mock function with given fields: repoName
-func (_m *Service) GetCharts(repoName string) (error, []model.Chart) {
-	ret := _m.Called(repoName)
+// GetChartMetadata provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetChartMetadata(ctx context.Context, repoName string, chartName string, chartVersion string) (model.ChartMetadata, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
+
+	var r0 model.ChartMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) model.ChartMetadata); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		r0 = ret.Get(0).(model.ChartMetadata)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCharts provides a mock function with given fields: ctx, repoName
+func (_m *Service) GetCharts(ctx context.Context, repoName string) (error, []model.Chart) {
+	ret := _m.Called(ctx, repoName)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(repoName)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, repoName)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 []model.Chart
-	if rf, ok := ret.Get(1).(func(string) []model.Chart); ok {
-		r1 = rf(repoName)
+	if rf, ok := ret.Get(1).(func(context.Context, string) []model.Chart); ok {
+		r1 = rf(ctx, repoName)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).([]model.Chart)
@@ -57,66 +329,240 @@ func (_m *Service) GetCharts(repoName string) (error, []model.Chart) {
 	return r0, r1
 }
 
-// GetRepos provides a mock function with given fields:
-func (_m *Service) GetRepos() []model.Repo {
-	ret := _m.Called()
+// GetDependencies provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetDependencies(ctx context.Context, repoName string, chartName string, chartVersion string) ([]model.Subchart, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
+
+	var r0 []model.Subchart
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []model.Subchart); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Subchart)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetImages provides a mock function with given fields: ctx, repoName, chartName, chartVersion, renderOptions
+func (_m *Service) GetImages(ctx context.Context, repoName string, chartName string, chartVersion string, renderOptions model.RenderOptions) (model.ImageInventoryResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, renderOptions)
+
+	var r0 model.ImageInventoryResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RenderOptions) model.ImageInventoryResponse); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r0 = ret.Get(0).(model.ImageInventoryResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetKubeVersion provides a mock function with given fields: ctx, kubeVersion
+func (_m *Service) GetKubeVersion(ctx context.Context, kubeVersion string) (model.KubernetesAPIVersion, error) {
+	ret := _m.Called(ctx, kubeVersion)
+
+	var r0 model.KubernetesAPIVersion
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.KubernetesAPIVersion); ok {
+		r0 = rf(ctx, kubeVersion)
+	} else {
+		r0 = ret.Get(0).(model.KubernetesAPIVersion)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, kubeVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetKubeVersions provides a mock function with given fields: ctx
+func (_m *Service) GetKubeVersions(ctx context.Context) ([]model.KubernetesAPIVersion, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.KubernetesAPIVersion
+	if rf, ok := ret.Get(0).(func(context.Context) []model.KubernetesAPIVersion); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.KubernetesAPIVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPolicyRules provides a mock function with given fields: ctx
+func (_m *Service) GetPolicyRules(ctx context.Context) ([]model.PolicyRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.PolicyRule
+	if rf, ok := ret.Get(0).(func(context.Context) []model.PolicyRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PolicyRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRBACReport provides a mock function with given fields: ctx, repoName, chartName, chartVersion, renderOptions
+func (_m *Service) GetRBACReport(ctx context.Context, repoName string, chartName string, chartVersion string, renderOptions model.RenderOptions) (model.RBACReport, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, renderOptions)
+
+	var r0 model.RBACReport
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RenderOptions) model.RBACReport); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r0 = ret.Get(0).(model.RBACReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.RenderOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRepo provides a mock function with given fields: ctx, repoName
+func (_m *Service) GetRepo(ctx context.Context, repoName string) (model.Repo, error) {
+	ret := _m.Called(ctx, repoName)
+
+	var r0 model.Repo
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.Repo); ok {
+		r0 = rf(ctx, repoName)
+	} else {
+		r0 = ret.Get(0).(model.Repo)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, repoName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRepos provides a mock function with given fields: ctx
+func (_m *Service) GetRepos(ctx context.Context) ([]model.Repo, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []model.Repo
-	if rf, ok := ret.Get(0).(func() []model.Repo); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []model.Repo); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Repo)
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetStringifiedManifests provides a mock function with given fields: repoName, chartName, chartVersion, hash
-func (_m *Service) GetStringifiedManifests(repoName string, chartName string, chartVersion string, hash string) string {
-	ret := _m.Called(repoName, chartName, chartVersion, hash)
+// GetStringifiedManifests provides a mock function with given fields: ctx, repoName, chartName, chartVersion, hash
+func (_m *Service) GetStringifiedManifests(ctx context.Context, repoName string, chartName string, chartVersion string, hash string) (string, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, hash)
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string, string, string) string); ok {
-		r0 = rf(repoName, chartName, chartVersion, hash)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) string); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, hash)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetTemplates provides a mock function with given fields: repoName, chartName, chartVersion
-func (_m *Service) GetTemplates(repoName string, chartName string, chartVersion string) []model.Template {
-	ret := _m.Called(repoName, chartName, chartVersion)
+// GetTemplates provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetTemplates(ctx context.Context, repoName string, chartName string, chartVersion string) ([]model.Template, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
 
 	var r0 []model.Template
-	if rf, ok := ret.Get(0).(func(string, string, string) []model.Template); ok {
-		r0 = rf(repoName, chartName, chartVersion)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []model.Template); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.Template)
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetValues provides a mock function with given fields: repoName, chartName, chartVersion
-func (_m *Service) GetValues(repoName string, chartName string, chartVersion string) (error, map[string]interface{}) {
-	ret := _m.Called(repoName, chartName, chartVersion)
+// GetValues provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetValues(ctx context.Context, repoName string, chartName string, chartVersion string) (error, map[string]interface{}) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
-		r0 = rf(repoName, chartName, chartVersion)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 map[string]interface{}
-	if rf, ok := ret.Get(1).(func(string, string, string) map[string]interface{}); ok {
-		r1 = rf(repoName, chartName, chartVersion)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) map[string]interface{}); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(map[string]interface{})
@@ -126,23 +572,188 @@ func (_m *Service) GetValues(repoName string, chartName string, chartVersion str
 	return r0, r1
 }
 
-// RenderManifest provides a mock function with given fields: repoName, chartName, chartVersion, values
-func (_m *Service) RenderManifest(repoName string, chartName string, chartVersion string, values []string) (error, model.ManifestResponse) {
-	ret := _m.Called(repoName, chartName, chartVersion, values)
+// GetValuesSchema provides a mock function with given fields: ctx, repoName, chartName, chartVersion
+func (_m *Service) GetValuesSchema(ctx context.Context, repoName string, chartName string, chartVersion string) (json.RawMessage, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion)
+
+	var r0 json.RawMessage
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) json.RawMessage); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(json.RawMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportAPIDeprecations provides a mock function with given fields: ctx, deprecations
+func (_m *Service) ImportAPIDeprecations(ctx context.Context, deprecations []model.APIDeprecation) error {
+	ret := _m.Called(ctx, deprecations)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.APIDeprecation) error); ok {
+		r0 = rf(ctx, deprecations)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ImportKubeVersions provides a mock function with given fields: ctx, kubeVersions
+func (_m *Service) ImportKubeVersions(ctx context.Context, kubeVersions []model.KubernetesAPIVersion) error {
+	ret := _m.Called(ctx, kubeVersions)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.KubernetesAPIVersion) error); ok {
+		r0 = rf(ctx, kubeVersions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PurgeCache provides a mock function with given fields: ctx, repoName, chartName
+func (_m *Service) PurgeCache(ctx context.Context, repoName string, chartName string) (int, error) {
+	ret := _m.Called(ctx, repoName, chartName)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int); ok {
+		r0 = rf(ctx, repoName, chartName)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, repoName, chartName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReloadPolicyRules provides a mock function with given fields: ctx
+func (_m *Service) ReloadPolicyRules(ctx context.Context) ([]model.PolicyRule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.PolicyRule
+	if rf, ok := ret.Get(0).(func(context.Context) []model.PolicyRule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.PolicyRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenderManifest provides a mock function with given fields: ctx, repoName, chartName, chartVersion, renderOptions
+func (_m *Service) RenderManifest(ctx context.Context, repoName string, chartName string, chartVersion string, renderOptions model.RenderOptions) (error, model.ManifestResponse) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, renderOptions)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string, string, []string) error); ok {
-		r0 = rf(repoName, chartName, chartVersion, values)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.RenderOptions) error); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
 	} else {
 		r0 = ret.Error(0)
 	}
 
 	var r1 model.ManifestResponse
-	if rf, ok := ret.Get(1).(func(string, string, string, []string) model.ManifestResponse); ok {
-		r1 = rf(repoName, chartName, chartVersion, values)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.RenderOptions) model.ManifestResponse); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, renderOptions)
 	} else {
 		r1 = ret.Get(1).(model.ManifestResponse)
 	}
 
 	return r0, r1
 }
+
+// UpdateKubeVersion provides a mock function with given fields: ctx, kubeVersion
+func (_m *Service) UpdateKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error {
+	ret := _m.Called(ctx, kubeVersion)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.KubernetesAPIVersion) error); ok {
+		r0 = rf(ctx, kubeVersion)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRepo provides a mock function with given fields: ctx, repo
+func (_m *Service) UpdateRepo(ctx context.Context, repo model.Repo) error {
+	ret := _m.Called(ctx, repo)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Repo) error); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadChart provides a mock function with given fields: ctx, archive
+func (_m *Service) UploadChart(ctx context.Context, archive []byte) (model.UploadedChart, error) {
+	ret := _m.Called(ctx, archive)
+
+	var r0 model.UploadedChart
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) model.UploadedChart); ok {
+		r0 = rf(ctx, archive)
+	} else {
+		r0 = ret.Get(0).(model.UploadedChart)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, archive)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateValues provides a mock function with given fields: ctx, repoName, chartName, chartVersion, valuesOptions
+func (_m *Service) ValidateValues(ctx context.Context, repoName string, chartName string, chartVersion string, valuesOptions model.ValuesOptions) (model.ValuesValidationResponse, error) {
+	ret := _m.Called(ctx, repoName, chartName, chartVersion, valuesOptions)
+
+	var r0 model.ValuesValidationResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, model.ValuesOptions) model.ValuesValidationResponse); ok {
+		r0 = rf(ctx, repoName, chartName, chartVersion, valuesOptions)
+	} else {
+		r0 = ret.Get(0).(model.ValuesValidationResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, model.ValuesOptions) error); ok {
+		r1 = rf(ctx, repoName, chartName, chartVersion, valuesOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"chart-viewer/pkg/model"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	repoKeyPrefix = "repo"
+	// legacyReposKey holds every repo as one JSON array, it is migrated to per-repo records before the first write.
+	legacyReposKey = "repos"
+)
+
+var (
+	ErrRepoExists  = errors.New("repo already exists")
+	ErrInvalidRepo = errors.New("invalid repo")
+)
+
+func repoKey(repoName string) string {
+	return cacheKey(repoKeyPrefix, repoName)
+}
+
+func (s *service) GetRepos(ctx context.Context) ([]model.Repo, error) {
+	keys, err := s.repository.Keys(ctx, repoKey(""))
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []model.Repo{}
+	if len(keys) == 0 {
+		_, err = s.getCache(ctx, legacyReposKey, &repos)
+		if err != nil {
+			return nil, err
+		}
+
+		return repos, nil
+	}
+
+	for _, key := range keys {
+		var repo model.Repo
+		found, err := s.getCache(ctx, key, &repo)
+		if err != nil {
+			return nil, err
+		}
+
+		if found {
+			repos = append(repos, repo)
+		}
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].Name < repos[j].Name
+	})
+
+	return repos, nil
+}
+
+func (s *service) GetRepo(ctx context.Context, repoName string) (model.Repo, error) {
+	if repoName == model.UploadRepoName {
+		return model.Repo{Name: model.UploadRepoName, Type: model.RepoTypeUpload}, nil
+	}
+
+	var repo model.Repo
+	found, err := s.getCache(ctx, repoKey(repoName), &repo)
+	if err != nil {
+		return model.Repo{}, err
+	}
+
+	if found {
+		return repo, nil
+	}
+
+	// Fall back to the legacy array for storages seeded before repos had their own records.
+	repos, err := s.GetRepos(ctx)
+	if err != nil {
+		return model.Repo{}, err
+	}
+
+	for _, r := range repos {
+		if r.Name == repoName {
+			return r, nil
+		}
+	}
+
+	return model.Repo{}, fmt.Errorf("repo %s: %w", repoName, ErrNotFound)
+}
+
+func (s *service) AddRepo(ctx context.Context, repo model.Repo) error {
+	err := s.migrateLegacyRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.Exists(ctx, repoKey(repo.Name))
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return fmt.Errorf("repo %s: %w", repo.Name, ErrRepoExists)
+	}
+
+	err = s.validateRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	return s.setCache(ctx, repoKey(repo.Name), repo, 0)
+}
+
+// UpdateRepo replaces the stored repo, credentials left empty keep their stored value. The cached charts of the repo
+// are purged, unless the repo is unchanged, so seeding the same repos again keeps them.
+func (s *service) UpdateRepo(ctx context.Context, repo model.Repo) error {
+	err := s.migrateLegacyRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	var existing model.Repo
+	found, err := s.getCache(ctx, repoKey(repo.Name), &existing)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("repo %s: %w", repo.Name, ErrNotFound)
+	}
+
+	repo = repo.WithCredentialsFrom(existing)
+	if reflect.DeepEqual(repo, existing) {
+		return nil
+	}
+
+	err = s.validateRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	err = s.setCache(ctx, repoKey(repo.Name), repo, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.PurgeCache(ctx, repo.Name, "")
+	return err
+}
+
+func (s *service) DeleteRepo(ctx context.Context, repoName string) error {
+	err := s.migrateLegacyRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.repository.Exists(ctx, repoKey(repoName))
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("repo %s: %w", repoName, ErrNotFound)
+	}
+
+	err = s.repository.Delete(ctx, repoKey(repoName))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.PurgeCache(ctx, repoName, "")
+	return err
+}
+
+// migrateLegacyRepos copies the legacy array to per-repo records and deletes it before the first write, otherwise
+// the repos it holds would no longer be listed once a record exists, nor found by updates and deletes.
+func (s *service) migrateLegacyRepos(ctx context.Context) error {
+	var repos []model.Repo
+	found, err := s.getCache(ctx, legacyReposKey, &repos)
+	if err != nil || !found {
+		return err
+	}
+
+	for _, repo := range repos {
+		exists, err := s.repository.Exists(ctx, repoKey(repo.Name))
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			continue
+		}
+
+		err = s.setCache(ctx, repoKey(repo.Name), repo, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.repository.Delete(ctx, legacyReposKey)
+}
+
+// validateRepo checks the repo fields and that its index.yaml can be fetched and parsed.
+func (s *service) validateRepo(repo model.Repo) error {
+	if repo.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRepo)
+	}
+
+	if strings.ContainsAny(repo.Name, ":/ ") {
+		return fmt.Errorf("%w: name %q must not contain ':', '/' or spaces", ErrInvalidRepo, repo.Name)
+	}
+
+	if repo.Name == model.UploadRepoName {
+		return fmt.Errorf("%w: name %q is reserved for uploaded charts", ErrInvalidRepo, repo.Name)
+	}
+
+	u, err := url.Parse(repo.URL)
+	switch {
+	case repo.Type != "" && repo.Type != model.RepoTypeHTTP && repo.Type != model.RepoTypeOCI:
+		return fmt.Errorf("%w: type %q must be %s or %s", ErrInvalidRepo, repo.Type, model.RepoTypeHTTP, model.RepoTypeOCI)
+	case err != nil || u.Host == "":
+		return fmt.Errorf("%w: url %q must be an absolute url", ErrInvalidRepo, repo.URL)
+	case repo.IsOCI() && u.Scheme != "oci":
+		return fmt.Errorf("%w: url %q of an oci repo must start with oci://", ErrInvalidRepo, repo.URL)
+	case !repo.IsOCI() && u.Scheme != "http" && u.Scheme != "https":
+		return fmt.Errorf("%w: url %q must be an http or https url", ErrInvalidRepo, repo.URL)
+	}
+
+	_, err = s.helmClient.GetIndex(repo)
+	if err != nil {
+		return fmt.Errorf("%w: cannot load the chart list: %s", ErrInvalidRepo, err)
+	}
+
+	return nil
+}
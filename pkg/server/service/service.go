@@ -3,74 +3,104 @@ package service
 import (
 	"bytes"
 	"chart-viewer/pkg/analyzer"
+	"chart-viewer/pkg/diff"
 	"chart-viewer/pkg/helm"
 	"chart-viewer/pkg/model"
 	"chart-viewer/pkg/repository"
+	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
+)
 
-	"gopkg.in/yaml.v3"
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrInvalidChart         = helm.ErrInvalidChart
+	ErrInvalidRenderOptions = helm.ErrInvalidRenderOptions
+	ErrInvalidPolicyRule    = analyzer.ErrInvalidRule
 )
 
+const defaultNamespace = "default"
+
+type InvalidValuesError = helm.InvalidValuesError
+
 type Service interface {
-	GetRepos() []model.Repo
-	GetCharts(repoName string) (error, []model.Chart)
-	GetValues(repoName, chartName, chartVersion string) (error, map[string]interface{})
-	GetTemplates(repoName, chartName, chartVersion string) ([]model.Template, error)
-	RenderManifest(repoName, chartName, chartVersion string, values []string) (error, model.ManifestResponse)
-	GetStringifiedManifests(repoName, chartName, chartVersion, hash string) string
-	GetChart(repoName string, chartName string, chartVersion string) (error, model.ChartDetail)
-	AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error)
+	GetRepos(ctx context.Context) ([]model.Repo, error)
+	GetRepo(ctx context.Context, repoName string) (model.Repo, error)
+	AddRepo(ctx context.Context, repo model.Repo) error
+	UpdateRepo(ctx context.Context, repo model.Repo) error
+	DeleteRepo(ctx context.Context, repoName string) error
+	GetCharts(ctx context.Context, repoName string) (error, []model.Chart)
+	GetValues(ctx context.Context, repoName, chartName, chartVersion string) (error, map[string]interface{})
+	GetTemplates(ctx context.Context, repoName, chartName, chartVersion string) ([]model.Template, error)
+	RenderManifest(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (error, model.ManifestResponse)
+	GetStringifiedManifests(ctx context.Context, repoName, chartName, chartVersion, hash string) (string, error)
+	GetChart(ctx context.Context, repoName string, chartName string, chartVersion string) (error, model.ChartDetail)
+	AnalyzeTemplate(ctx context.Context, templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error)
+	PurgeCache(ctx context.Context, repoName, chartName string) (int, error)
+	DeleteLegacyCache(ctx context.Context) (int, error)
+	UploadChart(ctx context.Context, archive []byte) (model.UploadedChart, error)
+	DiffTemplates(ctx context.Context, repoName, chartName, fromVersion, toVersion string) (model.TemplateDiffResponse, error)
+	DiffManifests(ctx context.Context, repoName, chartName, fromVersion, toVersion string, renderOptions model.RenderOptions) (model.ManifestDiffResponse, error)
+	DiffValues(ctx context.Context, repoName, chartName, fromVersion, toVersion string) (model.ValuesDiffResponse, error)
+	GetValuesSchema(ctx context.Context, repoName, chartName, chartVersion string) (json.RawMessage, error)
+	ValidateValues(ctx context.Context, repoName, chartName, chartVersion string, valuesOptions model.ValuesOptions) (model.ValuesValidationResponse, error)
+	GetChartMetadata(ctx context.Context, repoName, chartName, chartVersion string) (model.ChartMetadata, error)
+	GetDependencies(ctx context.Context, repoName, chartName, chartVersion string) ([]model.Subchart, error)
+	AnalyzeSubcharts(ctx context.Context, subcharts []model.Subchart, kubeVersion string) ([]model.AnalyticsResult, error)
+	AnalyzeManifests(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions, kubeVersion string, asCapabilities bool) (model.ManifestAnalysisResponse, error)
+	GetKubeVersions(ctx context.Context) ([]model.KubernetesAPIVersion, error)
+	GetKubeVersion(ctx context.Context, kubeVersion string) (model.KubernetesAPIVersion, error)
+	AddKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error
+	UpdateKubeVersion(ctx context.Context, kubeVersion model.KubernetesAPIVersion) error
+	DeleteKubeVersion(ctx context.Context, kubeVersion string) error
+	ImportKubeVersions(ctx context.Context, kubeVersions []model.KubernetesAPIVersion) error
+	ImportAPIDeprecations(ctx context.Context, deprecations []model.APIDeprecation) error
+	CheckPolicies(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.PolicyCheckResponse, error)
+	GetPolicyRules(ctx context.Context) ([]model.PolicyRule, error)
+	ReloadPolicyRules(ctx context.Context) ([]model.PolicyRule, error)
+	GetImages(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.ImageInventoryResponse, error)
+	GetRBACReport(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.RBACReport, error)
+	DiffRBAC(ctx context.Context, repoName, chartName, fromVersion, toVersion string, renderOptions model.RenderOptions) (model.RBACDiffResponse, error)
 }
 
 type service struct {
 	helmClient helm.Helm
 	repository repository.Repository
 	analyzer   analyzer.Analytic
+	cacheTTL   CacheTTL
 }
 
-func NewService(helmClient helm.Helm, repository repository.Repository, analyzer analyzer.Analytic) Service {
+func NewService(helmClient helm.Helm, repository repository.Repository, analyzer analyzer.Analytic, cacheTTL CacheTTL) Service {
 	return &service{
 		helmClient: helmClient,
 		repository: repository,
 		analyzer:   analyzer,
+		cacheTTL:   cacheTTL,
 	}
 }
 
-func (s *service) GetRepos() []model.Repo {
-	stringifiedRepos := s.repository.Get("repos")
-	repos := []model.Repo{}
-	_ = json.Unmarshal([]byte(stringifiedRepos), &repos)
-
-	return repos
-}
-
-func (s *service) GetCharts(repoName string) (error, []model.Chart) {
-	stringifiedCharts := s.repository.Get(repoName)
+func (s *service) GetCharts(ctx context.Context, repoName string) (error, []model.Chart) {
 	var cachedCharts []model.Chart
-	_ = json.Unmarshal([]byte(stringifiedCharts), &cachedCharts)
+	cacheKey := chartsCacheKey(repoName)
+	_, err := s.getCache(ctx, cacheKey, &cachedCharts)
+	if err != nil {
+		return err, nil
+	}
 
 	if len(cachedCharts) != 0 {
 		log.Printf("%s chart detail fetched from cache\n", repoName)
 		return nil, cachedCharts
 	}
 
-	url := s.getUrl(repoName)
-
-	log.Printf("out going call: %s\n", url)
-	response, err := http.Get(url + "/index.yaml")
+	repo, err := s.GetRepo(ctx, repoName)
 	if err != nil {
 		return err, nil
 	}
 
-	content, err := ioutil.ReadAll(response.Body)
-
-	repoDetail := new(model.RepoDetailResponse)
-	err = yaml.Unmarshal(content, &repoDetail)
+	repoDetail, err := s.helmClient.GetIndex(repo)
 	if err != nil {
 		return err, nil
 	}
@@ -90,92 +120,248 @@ func (s *service) GetCharts(repoName string) (error, []model.Chart) {
 		})
 	}
 
-	chartsByte, _ := json.Marshal(charts)
-	s.repository.Set(repoName, string(chartsByte))
+	err = s.setCache(ctx, cacheKey, charts, s.cacheTTL.RepoIndex)
+	if err != nil {
+		return err, nil
+	}
 
 	return nil, charts
 }
 
-func (s *service) GetValues(repoName, chartName, chartVersion string) (error, map[string]interface{}) {
-	cacheKey := fmt.Sprintf("value-%s-%s-%s", repoName, chartName, chartVersion)
-	stringifiedValues := s.repository.Get(cacheKey)
+func (s *service) GetValues(ctx context.Context, repoName, chartName, chartVersion string) (error, map[string]interface{}) {
+	cacheKey := valuesCacheKey(repoName, chartName, chartVersion)
 	var cachedValues map[string]interface{}
-	_ = json.Unmarshal([]byte(stringifiedValues), &cachedValues)
+	_, err := s.getCache(ctx, cacheKey, &cachedValues)
+	if err != nil {
+		return err, nil
+	}
+
 	if len(cachedValues) != 0 {
-		log.Printf("value-%s-%s-%s chart values fetched from cache\n", repoName, chartName, chartVersion)
+		log.Printf("%s chart values fetched from cache\n", cacheKey)
 		return nil, cachedValues
 	}
 
-	var url string
-	repos := s.GetRepos()
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return err, nil
+	}
 
-	for _, r := range repos {
-		if r.Name == repoName {
-			url = r.URL
-		}
+	err, values := s.helmClient.GetValues(repo, chartName, chartVersion)
+	if err != nil {
+		return err, nil
 	}
 
-	err, values := s.helmClient.GetValues(url, chartName, chartVersion)
+	err = s.setCache(ctx, cacheKey, values, s.cacheTTL.Values)
 	if err != nil {
 		return err, nil
 	}
-	valuesByte, _ := json.Marshal(values)
-	s.repository.Set(cacheKey, string(valuesByte))
 
 	return nil, values
 }
 
-func (s *service) GetTemplates(repoName, chartName, chartVersion string) ([]model.Template, error) {
-	cacheKey := fmt.Sprintf("template-%s-%s-%s", repoName, chartName, chartVersion)
-	stringifiedTemplates := s.repository.Get(cacheKey)
+func (s *service) GetChartMetadata(ctx context.Context, repoName, chartName, chartVersion string) (model.ChartMetadata, error) {
+	cacheKey := metadataCacheKey(repoName, chartName, chartVersion)
+	var metadata model.ChartMetadata
+	found, err := s.getCache(ctx, cacheKey, &metadata)
+	if err != nil {
+		return model.ChartMetadata{}, err
+	}
+
+	if found {
+		log.Printf("%s chart metadata fetched from cache\n", cacheKey)
+		return metadata, nil
+	}
+
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return model.ChartMetadata{}, err
+	}
+
+	metadata, err = s.helmClient.GetChartMetadata(repo, chartName, chartVersion)
+	if err != nil {
+		return model.ChartMetadata{}, err
+	}
+
+	err = s.setCache(ctx, cacheKey, metadata, s.cacheTTL.Values)
+	if err != nil {
+		return model.ChartMetadata{}, err
+	}
+
+	return metadata, nil
+}
+
+// GetValuesSchema returns the values.schema.json of the chart, ErrNotFound when the chart has none.
+func (s *service) GetValuesSchema(ctx context.Context, repoName, chartName, chartVersion string) (json.RawMessage, error) {
+	cacheKey := schemaCacheKey(repoName, chartName, chartVersion)
+	var schema json.RawMessage
+	found, err := s.getCache(ctx, cacheKey, &schema)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		repo, err := s.GetRepo(ctx, repoName)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err = s.helmClient.GetValuesSchema(repo, chartName, chartVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		// charts without a schema are cached as null
+		err = s.setCache(ctx, cacheKey, schema, s.cacheTTL.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(schema) == 0 || string(schema) == "null" {
+		return nil, fmt.Errorf("%w: chart %s %s has no values.schema.json", ErrNotFound, chartName, chartVersion)
+	}
+
+	return schema, nil
+}
+
+func (s *service) ValidateValues(ctx context.Context, repoName, chartName, chartVersion string, valuesOptions model.ValuesOptions) (model.ValuesValidationResponse, error) {
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return model.ValuesValidationResponse{}, err
+	}
+
+	schemaErrors, err := s.helmClient.ValidateValues(repo, chartName, chartVersion, valuesOptions)
+	if err != nil {
+		return model.ValuesValidationResponse{}, err
+	}
+
+	return model.ValuesValidationResponse{
+		Valid:  len(schemaErrors) == 0,
+		Errors: schemaErrors,
+	}, nil
+}
+
+func (s *service) GetTemplates(ctx context.Context, repoName, chartName, chartVersion string) ([]model.Template, error) {
+	cacheKey := templatesCacheKey(repoName, chartName, chartVersion)
 	var cachedTemplates []model.Template
-	_ = json.Unmarshal([]byte(stringifiedTemplates), &cachedTemplates)
+	_, err := s.getCache(ctx, cacheKey, &cachedTemplates)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(cachedTemplates) != 0 {
-		log.Printf("template-%s-%s-%s chart values fetched from cache\n", repoName, chartName, chartVersion)
+		log.Printf("%s chart templates fetched from cache\n", cacheKey)
 		return cachedTemplates, nil
 	}
 
-	var url string
-	repos := s.GetRepos()
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, r := range repos {
-		if r.Name == repoName {
-			url = r.URL
-		}
+	templates, err := s.helmClient.GetManifest(repo, chartName, chartVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	templates, err := s.helmClient.GetManifest(url, chartName, chartVersion)
+	err = s.setCache(ctx, cacheKey, templates, s.cacheTTL.Templates)
 	if err != nil {
 		return nil, err
 	}
-	templatesByte, _ := json.Marshal(templates)
-	s.repository.Set(cacheKey, string(templatesByte))
 
 	return templates, nil
 }
 
-func (s *service) RenderManifest(repoName, chartName, chartVersion string, values []string) (error, model.ManifestResponse) {
-	hash := hashFileContent(values[0])
-	cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", repoName, chartName, chartVersion, hash)
-	stringifiedManifest := s.repository.Get(cacheKey)
+func (s *service) DiffTemplates(ctx context.Context, repoName, chartName, fromVersion, toVersion string) (model.TemplateDiffResponse, error) {
+	fromTemplates, err := s.GetTemplates(ctx, repoName, chartName, fromVersion)
+	if err != nil {
+		return model.TemplateDiffResponse{}, err
+	}
+
+	toTemplates, err := s.GetTemplates(ctx, repoName, chartName, toVersion)
+	if err != nil {
+		return model.TemplateDiffResponse{}, err
+	}
+
+	files, err := diff.Templates(fromTemplates, toTemplates, fromVersion, toVersion)
+	if err != nil {
+		return model.TemplateDiffResponse{}, err
+	}
+
+	return model.TemplateDiffResponse{
+		From:  fromVersion,
+		To:    toVersion,
+		Files: files,
+	}, nil
+}
+
+func (s *service) DiffValues(ctx context.Context, repoName, chartName, fromVersion, toVersion string) (model.ValuesDiffResponse, error) {
+	err, fromValues := s.GetValues(ctx, repoName, chartName, fromVersion)
+	if err != nil {
+		return model.ValuesDiffResponse{}, err
+	}
+
+	err, toValues := s.GetValues(ctx, repoName, chartName, toVersion)
+	if err != nil {
+		return model.ValuesDiffResponse{}, err
+	}
+
+	return model.ValuesDiffResponse{
+		From:    fromVersion,
+		To:      toVersion,
+		Changes: diff.Values(fromValues, toValues),
+	}, nil
+}
+
+// DiffManifests renders both versions with the same options and compares the resulting resources.
+func (s *service) DiffManifests(ctx context.Context, repoName, chartName, fromVersion, toVersion string, renderOptions model.RenderOptions) (model.ManifestDiffResponse, error) {
+	err, fromManifests := s.RenderManifest(ctx, repoName, chartName, fromVersion, renderOptions)
+	if err != nil {
+		return model.ManifestDiffResponse{}, err
+	}
+
+	err, toManifests := s.RenderManifest(ctx, repoName, chartName, toVersion, renderOptions)
+	if err != nil {
+		return model.ManifestDiffResponse{}, err
+	}
+
+	resources, warnings, err := diff.Manifests(fromManifests.Manifests, toManifests.Manifests, fromVersion, toVersion)
+	if err != nil {
+		return model.ManifestDiffResponse{}, err
+	}
+
+	return model.ManifestDiffResponse{
+		From:      fromVersion,
+		To:        toVersion,
+		Resources: resources,
+		Warnings:  warnings,
+	}, nil
+}
+
+func (s *service) RenderManifest(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (error, model.ManifestResponse) {
+	hash, err := hashRenderOptions(renderOptions)
+	if err != nil {
+		return err, model.ManifestResponse{}
+	}
+
+	cacheKey := manifestsCacheKey(repoName, chartName, chartVersion, hash)
 	var cachedManifests model.ManifestResponse
-	_ = json.Unmarshal([]byte(stringifiedManifest), &cachedManifests)
+	found, err := s.getCache(ctx, cacheKey, &cachedManifests)
+	if err != nil {
+		return err, model.ManifestResponse{}
+	}
 
-	if stringifiedManifest != "" {
+	if found {
 		log.Printf("manifest fetched from cache with key: %s\n", cacheKey)
 		return nil, cachedManifests
 	}
 
-	var url string
-	repos := s.GetRepos()
-
-	for _, r := range repos {
-		if r.Name == repoName {
-			url = r.URL
-		}
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return err, model.ManifestResponse{}
 	}
 
-	err, manifests := s.helmClient.RenderManifest(url, chartName, chartVersion, values)
+	err, manifests := s.helmClient.RenderManifest(repo, chartName, chartVersion, renderOptions)
 	if err != nil {
 		return err, model.ManifestResponse{}
 	}
@@ -186,53 +372,304 @@ func (s *service) RenderManifest(repoName, chartName, chartVersion string, value
 		Manifests: manifests,
 	}
 
-	manifestsByte, _ := json.Marshal(manifestsResponse)
-	s.repository.Set(cacheKey, string(manifestsByte))
+	err = s.setCache(ctx, cacheKey, manifestsResponse, s.cacheTTL.Manifests)
+	if err != nil {
+		return err, model.ManifestResponse{}
+	}
 
 	return nil, manifestsResponse
 }
 
-func (s *service) GetStringifiedManifests(repoName, chartName, chartVersion, hash string) string {
-	cacheKey := fmt.Sprintf("manifests-%s-%s-%s-%s", repoName, chartName, chartVersion, hash)
+func (s *service) GetStringifiedManifests(ctx context.Context, repoName, chartName, chartVersion, hash string) (string, error) {
+	cacheKey := manifestsCacheKey(repoName, chartName, chartVersion, hash)
 	var cachedManifests model.ManifestResponse
-	stringifiedManifest := s.repository.Get(cacheKey)
+	found, err := s.getCache(ctx, cacheKey, &cachedManifests)
+	if err != nil {
+		return "", err
+	}
 
-	_ = json.Unmarshal([]byte(stringifiedManifest), &cachedManifests)
-	return stringfyManifest(cachedManifests.Manifests)
+	if !found {
+		return "", ErrNotFound
+	}
+
+	return stringfyManifest(cachedManifests.Manifests), nil
 }
 
-func (s *service) GetChart(repoName string, chartName string, chartVersion string) (error, model.ChartDetail) {
-	err, values := s.GetValues(repoName, chartName, chartVersion)
+func (s *service) GetChart(ctx context.Context, repoName string, chartName string, chartVersion string) (error, model.ChartDetail) {
+	err, values := s.GetValues(ctx, repoName, chartName, chartVersion)
+	if err != nil {
+		return err, model.ChartDetail{}
+	}
+
+	templates, err := s.GetTemplates(ctx, repoName, chartName, chartVersion)
+	if err != nil {
+		return err, model.ChartDetail{}
+	}
+
+	dependencies, err := s.GetDependencies(ctx, repoName, chartName, chartVersion)
 	if err != nil {
 		return err, model.ChartDetail{}
 	}
-	templates, err := s.GetTemplates(repoName, chartName, chartVersion)
 
 	return nil, model.ChartDetail{
-		Values:    values,
-		Templates: templates,
+		Values:       values,
+		Templates:    templates,
+		Dependencies: dependencies,
 	}
 }
 
-func (s *service) AnalyzeTemplate(templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error) {
-	stringifiedApiVersion := s.repository.Get("api-versions")
-	var kubeAPIVersions []model.KubernetesAPIVersion
-	_ = json.Unmarshal([]byte(stringifiedApiVersion), &kubeAPIVersions)
+// GetDependencies returns the subchart tree of a chart version, it is cached along with the templates.
+func (s *service) GetDependencies(ctx context.Context, repoName, chartName, chartVersion string) ([]model.Subchart, error) {
+	cacheKey := dependenciesCacheKey(repoName, chartName, chartVersion)
+	var dependencies []model.Subchart
+	found, err := s.getCache(ctx, cacheKey, &dependencies)
+	if err != nil {
+		return nil, err
+	}
 
-	var kubeAPIVersion model.KubernetesAPIVersion
-	for _, k := range kubeAPIVersions {
-		if k.KubeVersion == kubeVersion {
-			kubeAPIVersion = k
-		}
+	if found {
+		log.Printf("%s chart dependencies fetched from cache\n", cacheKey)
+		return dependencies, nil
+	}
+
+	repo, err := s.GetRepo(ctx, repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err = s.helmClient.GetDependencies(repo, chartName, chartVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.setCache(ctx, cacheKey, dependencies, s.cacheTTL.Templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return dependencies, nil
+}
+
+func (s *service) AnalyzeTemplate(ctx context.Context, templates []model.Template, kubeVersion string) ([]model.AnalyticsResult, error) {
+	kubeAPIVersion, err := s.getKubeAPIVersion(ctx, kubeVersion)
+	if err != nil {
+		return nil, err
 	}
 
 	return s.analyzer.Analyze(templates, kubeAPIVersion)
 }
 
-func hashFileContent(fileLocation string) string {
-	valuesFileContent, _ := ioutil.ReadFile(fileLocation)
-	hash := md5.Sum(valuesFileContent)
-	return fmt.Sprintf("%x", hash)
+func (s *service) AnalyzeSubcharts(ctx context.Context, subcharts []model.Subchart, kubeVersion string) ([]model.AnalyticsResult, error) {
+	kubeAPIVersion, err := s.getKubeAPIVersion(ctx, kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.analyzer.AnalyzeSubcharts(subcharts, kubeAPIVersion)
+}
+
+// AnalyzeManifests renders the chart and checks every rendered resource against kubeVersion. With asCapabilities
+// the chart is rendered for kubeVersion, with its API versions available to .Capabilities.APIVersions.
+func (s *service) AnalyzeManifests(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions, kubeVersion string, asCapabilities bool) (model.ManifestAnalysisResponse, error) {
+	kubeAPIVersion, err := s.getKubeAPIVersion(ctx, kubeVersion)
+	if err != nil {
+		return model.ManifestAnalysisResponse{}, err
+	}
+
+	if asCapabilities {
+		renderOptions.KubeVersion = kubeVersion
+		if kubeAPIVersion.KubeVersion == kubeVersion && kubeAPIVersion.Release != "" {
+			renderOptions.KubeVersion = kubeAPIVersion.Release
+		}
+		if _, ok := minorKubeVersion(renderOptions.KubeVersion); !ok {
+			return model.ManifestAnalysisResponse{}, fmt.Errorf("%w: kube version %s has no release to render for", ErrInvalidRenderOptions, kubeVersion)
+		}
+		renderOptions.APIVersions = append(renderOptions.APIVersions, kubeAPIVersion.APIVersions...)
+	}
+
+	err, manifests := s.RenderManifest(ctx, repoName, chartName, chartVersion, renderOptions)
+	if err != nil {
+		return model.ManifestAnalysisResponse{}, err
+	}
+
+	resources, err := s.analyzer.AnalyzeManifests(manifests.Manifests, kubeAPIVersion)
+	if err != nil {
+		return model.ManifestAnalysisResponse{}, err
+	}
+
+	findings, err := s.findDeprecations(ctx, manifests.Manifests, kubeAPIVersion.ReleaseVersion())
+	if err != nil {
+		return model.ManifestAnalysisResponse{}, err
+	}
+
+	return model.ManifestAnalysisResponse{
+		KubeVersion:  kubeAPIVersion.KubeVersion,
+		Resources:    resources,
+		Deprecations: findings,
+	}, nil
+}
+
+// ImportAPIDeprecations replaces the deprecation data the rendered resources are checked with.
+func (s *service) ImportAPIDeprecations(ctx context.Context, deprecations []model.APIDeprecation) error {
+	return s.setCache(ctx, apiDeprecationsKey, deprecations, 0)
+}
+
+// findDeprecations looks the resources up in the deprecation data, kube versions without a release have nothing to
+// compare the deprecations with.
+func (s *service) findDeprecations(ctx context.Context, manifests []model.Manifest, kubeVersion string) ([]model.DeprecationFinding, error) {
+	if _, ok := minorKubeVersion(kubeVersion); !ok {
+		return []model.DeprecationFinding{}, nil
+	}
+
+	var deprecations []model.APIDeprecation
+	_, err := s.getCache(ctx, apiDeprecationsKey, &deprecations)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.analyzer.FindDeprecations(manifests, deprecations, kubeVersion)
+}
+
+// CheckPolicies renders the chart and runs the policy rules over the rendered resources.
+func (s *service) CheckPolicies(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.PolicyCheckResponse, error) {
+	err, manifests := s.RenderManifest(ctx, repoName, chartName, chartVersion, renderOptions)
+	if err != nil {
+		return model.PolicyCheckResponse{}, err
+	}
+
+	findings, err := s.analyzer.CheckPolicies(manifests.Manifests)
+	if err != nil {
+		return model.PolicyCheckResponse{}, err
+	}
+
+	return model.PolicyCheckResponse{Findings: findings}, nil
+}
+
+// GetImages renders the chart and lists the images of the containers of every rendered workload.
+func (s *service) GetImages(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.ImageInventoryResponse, error) {
+	err, manifests := s.RenderManifest(ctx, repoName, chartName, chartVersion, renderOptions)
+	if err != nil {
+		return model.ImageInventoryResponse{}, err
+	}
+
+	images, err := s.analyzer.ListImages(manifests.Manifests)
+	if err != nil {
+		return model.ImageInventoryResponse{}, err
+	}
+
+	return model.ImageInventoryResponse{Images: images}, nil
+}
+
+// GetRBACReport renders the chart and builds the permission matrix of its roles and bindings.
+func (s *service) GetRBACReport(ctx context.Context, repoName, chartName, chartVersion string, renderOptions model.RenderOptions) (model.RBACReport, error) {
+	err, manifests := s.RenderManifest(ctx, repoName, chartName, chartVersion, renderOptions)
+	if err != nil {
+		return model.RBACReport{}, err
+	}
+
+	return s.analyzer.AnalyzeRBAC(manifests.Manifests, releaseNamespace(renderOptions))
+}
+
+// DiffRBAC renders both versions with the same options and compares their permission matrices.
+func (s *service) DiffRBAC(ctx context.Context, repoName, chartName, fromVersion, toVersion string, renderOptions model.RenderOptions) (model.RBACDiffResponse, error) {
+	fromReport, err := s.GetRBACReport(ctx, repoName, chartName, fromVersion, renderOptions)
+	if err != nil {
+		return model.RBACDiffResponse{}, err
+	}
+
+	toReport, err := s.GetRBACReport(ctx, repoName, chartName, toVersion, renderOptions)
+	if err != nil {
+		return model.RBACDiffResponse{}, err
+	}
+
+	findings := make([]model.RBACFinding, 0)
+	for _, finding := range toReport.Findings {
+		if !containsRBACFinding(fromReport.Findings, finding) {
+			findings = append(findings, finding)
+		}
+	}
+
+	return model.RBACDiffResponse{
+		From:     fromVersion,
+		To:       toVersion,
+		Changes:  diff.RBAC(fromReport.Permissions, toReport.Permissions),
+		Findings: findings,
+	}, nil
+}
+
+func (s *service) GetPolicyRules(ctx context.Context) ([]model.PolicyRule, error) {
+	return s.analyzer.PolicyRules(), nil
+}
+
+// ReloadPolicyRules reads the user-defined rule files again and returns the rules now in use.
+func (s *service) ReloadPolicyRules(ctx context.Context) ([]model.PolicyRule, error) {
+	err := s.analyzer.ReloadPolicyRules()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.analyzer.PolicyRules(), nil
+}
+
+// releaseNamespace is the namespace of the resources rendered without one, helm renders in default unless told
+// otherwise.
+func releaseNamespace(renderOptions model.RenderOptions) string {
+	if renderOptions.Namespace == "" {
+		return defaultNamespace
+	}
+	return renderOptions.Namespace
+}
+
+func containsRBACFinding(findings []model.RBACFinding, finding model.RBACFinding) bool {
+	for _, f := range findings {
+		if f == finding {
+			return true
+		}
+	}
+	return false
+}
+
+// getKubeAPIVersion resolves the kube version to analyze against, no kube version analyzes against an empty API.
+func (s *service) getKubeAPIVersion(ctx context.Context, kubeVersion string) (model.KubernetesAPIVersion, error) {
+	if kubeVersion == "" {
+		return model.KubernetesAPIVersion{}, nil
+	}
+
+	return s.GetKubeVersion(ctx, kubeVersion)
+}
+
+// UploadChart stores a packaged chart, it is then served by the uploads repo with its ID as chart name.
+func (s *service) UploadChart(ctx context.Context, archive []byte) (model.UploadedChart, error) {
+	uploadedChart, err := s.helmClient.UploadChart(archive)
+	if err != nil {
+		return model.UploadedChart{}, err
+	}
+
+	err = s.repository.Delete(ctx, chartsCacheKey(model.UploadRepoName))
+	if err != nil {
+		return model.UploadedChart{}, err
+	}
+
+	log.Printf("chart %s:%s uploaded as %s\n", uploadedChart.Name, uploadedChart.Version, uploadedChart.ID)
+	return uploadedChart, nil
+}
+
+// hashRenderOptions identifies the rendered manifests by every render input. A single values document without
+// overrides or render settings is hashed on its own so that manifest links created from one values document stay valid.
+func hashRenderOptions(renderOptions model.RenderOptions) (string, error) {
+	var content []byte
+	if len(renderOptions.Values) == 1 && !renderOptions.HasOverrides() && !renderOptions.HasSettings() {
+		content = []byte(renderOptions.Values[0])
+	} else {
+		encoded, err := json.Marshal(renderOptions)
+		if err != nil {
+			return "", err
+		}
+		content = encoded
+	}
+
+	hash := md5.Sum(content)
+	return fmt.Sprintf("%x", hash), nil
 }
 
 func getVersion(name string, entries map[string][]model.ChartResponse) []string {
@@ -256,14 +693,3 @@ func stringfyManifest(manifests []model.Manifest) string {
 
 	return buffer.String()
 }
-
-func (s *service) getUrl(repoName string) string {
-	repos := s.GetRepos()
-	for _, r := range repos {
-		if r.Name == repoName {
-			return r.URL
-		}
-	}
-
-	return ""
-}
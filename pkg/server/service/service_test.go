@@ -1,24 +1,31 @@
 package service_test
 
 import (
+	"chart-viewer/pkg/analyzer"
 	helmMock "chart-viewer/pkg/helm/mocks"
 	"chart-viewer/pkg/model"
+	"chart-viewer/pkg/repository"
 	repoMock "chart-viewer/pkg/repository/mocks"
 	"chart-viewer/pkg/server/service"
+	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
-	"io/ioutil"
+	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 func TestService_GetRepos(t *testing.T) {
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	stringifiedRepos := "[{\"name\":\"stable\",\"url\":\"https://chart.stable.com\"}]"
-	repository.On("Get", "repos").Return(stringifiedRepos).Once()
-	svc := service.NewService(helm, repository)
-	charts := svc.GetRepos()
+	stringifiedRepo := "{\"name\":\"stable\",\"url\":\"https://chart.stable.com\"}"
+	repository.On("Keys", mock.Anything, "repo:").Return([]string{"repo:stable"}, nil).Once()
+	repository.On("Get", mock.Anything, "repo:stable").Return(stringifiedRepo, true, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	charts, err := svc.GetRepos(context.Background())
+	assert.NoError(t, err)
 
 	expectedCharts := []model.Repo{
 		{
@@ -30,14 +37,147 @@ func TestService_GetRepos(t *testing.T) {
 	assert.Equal(t, expectedCharts, charts)
 }
 
+func TestService_GetReposFromLegacyList(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	stringifiedRepos := "[{\"name\":\"stable\",\"url\":\"https://chart.stable.com\"}]"
+	repository.On("Keys", mock.Anything, "repo:").Return([]string{}, nil).Once()
+	repository.On("Get", mock.Anything, "repos").Return(stringifiedRepos, true, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	repos, err := svc.GetRepos(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []model.Repo{{Name: "stable", URL: "https://chart.stable.com"}}, repos)
+}
+
+func TestService_AddRepo(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Exists", mock.Anything, "repo:internal").Return(false, nil).Once()
+	repository.On("Set", mock.Anything, "repo:internal", "{\"name\":\"internal\",\"url\":\"https://charts.internal\"}", time.Duration(0)).Return(nil).Once()
+	helm.On("GetIndex", model.Repo{Name: "internal", URL: "https://charts.internal"}).Return(model.RepoDetailResponse{ApiVersion: "v1"}, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.AddRepo(context.Background(), model.Repo{Name: "internal", URL: "https://charts.internal"})
+	assert.NoError(t, err)
+
+	repository.AssertExpectations(t)
+}
+
+func TestService_UpdateRepoKeepsCredentials(t *testing.T) {
+	stored := "{\"name\":\"internal\",\"url\":\"https://charts.internal\",\"username\":\"admin\",\"password\":\"secret\"}"
+	updated := model.Repo{Name: "internal", URL: "https://charts.internal/v2", Username: "admin", Password: "secret"}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Get", mock.Anything, "repo:internal").Return(stored, true, nil).Once()
+	repository.On("Set", mock.Anything, "repo:internal", "{\"name\":\"internal\",\"url\":\"https://charts.internal/v2\",\"username\":\"admin\",\"password\":\"secret\"}", time.Duration(0)).Return(nil).Once()
+	repository.On("Keys", mock.Anything, mock.Anything).Return([]string{}, nil)
+	repository.On("Exists", mock.Anything, "charts:internal").Return(false, nil).Once()
+	helm.On("GetIndex", updated).Return(model.RepoDetailResponse{ApiVersion: "v1"}, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.UpdateRepo(context.Background(), model.Repo{Name: "internal", URL: "https://charts.internal/v2"})
+	assert.NoError(t, err)
+
+	repository.AssertExpectations(t)
+	helm.AssertExpectations(t)
+}
+
+func TestService_UpdateRepoUnchangedKeepsCache(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repo:internal", "{\"name\":\"internal\",\"url\":\"https://charts.internal\",\"token\":\"secret\"}", 0))
+	assert.NoError(t, storage.Set(ctx, "charts:internal", "[]", 0))
+	assert.NoError(t, storage.Set(ctx, "value:internal:nginx:0.1.0", "{}", 0))
+
+	svc := service.NewService(new(helmMock.Helm), storage, nil, service.CacheTTL{})
+	err := svc.UpdateRepo(ctx, model.Repo{Name: "internal", URL: "https://charts.internal"})
+	assert.NoError(t, err)
+
+	keys, err := storage.Keys(ctx, "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"repo:internal", "charts:internal", "value:internal:nginx:0.1.0"}, keys)
+}
+
+func TestService_AddRepoAlreadyExists(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Exists", mock.Anything, "repo:stable").Return(true, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.AddRepo(context.Background(), model.Repo{Name: "stable", URL: "https://charts.helm.sh/stable"})
+	assert.True(t, errors.Is(err, service.ErrRepoExists))
+}
+
+func TestService_AddRepoUnreachableIndex(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Exists", mock.Anything, "repo:internal").Return(false, nil).Once()
+	helm.On("GetIndex", model.Repo{Name: "internal", URL: "https://charts.internal"}).Return(model.RepoDetailResponse{}, errors.New("404 Not Found")).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.AddRepo(context.Background(), model.Repo{Name: "internal", URL: "https://charts.internal"})
+	assert.True(t, errors.Is(err, service.ErrInvalidRepo))
+	repository.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_AddRepoInvalidName(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Exists", mock.Anything, "repo:a:b").Return(false, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.AddRepo(context.Background(), model.Repo{Name: "a:b", URL: "https://charts.internal"})
+	assert.True(t, errors.Is(err, service.ErrInvalidRepo))
+}
+
+func TestService_AddRepoMigratesLegacyList(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repos", "[{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"},{\"name\":\"bitnami\",\"url\":\"https://charts.bitnami.com/bitnami\"}]", 0))
+
+	internal := model.Repo{Name: "internal", URL: "https://charts.internal"}
+	helm := new(helmMock.Helm)
+	helm.On("GetIndex", internal).Return(model.RepoDetailResponse{ApiVersion: "v1"}, nil).Once()
+	svc := service.NewService(helm, storage, nil, service.CacheTTL{})
+
+	assert.NoError(t, svc.AddRepo(ctx, internal))
+	assert.NoError(t, svc.DeleteRepo(ctx, "bitnami"))
+
+	repos, err := svc.GetRepos(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Repo{internal, {Name: "stable", URL: "https://charts.helm.sh/stable"}}, repos)
+
+	exists, err := storage.Exists(ctx, "repos")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestService_DeleteRepoNotFound(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repos").Return("", false, nil).Once()
+	repository.On("Exists", mock.Anything, "repo:internal").Return(false, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	err := svc.DeleteRepo(context.Background(), "internal")
+	assert.True(t, errors.Is(err, service.ErrNotFound))
+}
+
 func TestService_GetChartsFromCache(t *testing.T) {
 	stringifiedChart := "[{\"name\":\"discourse\",\"versions\":[\"0.3.5\",\"0.3.4\",\"0.3.3\",\"0.3.2\"]}]"
 
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	repository.On("Get", "stable").Return(stringifiedChart)
-	svc := service.NewService(helm, repository)
-	err, charts := svc.GetCharts("stable")
+	repository.On("Get", mock.Anything, "charts:stable").Return(stringifiedChart, true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, charts := svc.GetCharts(context.Background(), "stable")
 	assert.NoError(t, err)
 
 	expectedCharts := []model.Chart{
@@ -57,9 +197,9 @@ func TestService_GetValuesFromCache(t *testing.T) {
 
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	repository.On("Get", "value-stable-app-deploy-v0.0.1").Return(stringifiedValues)
-	svc := service.NewService(helm, repository)
-	err, values := svc.GetValues("stable", "app-deploy", "v0.0.1")
+	repository.On("Get", mock.Anything, "value:stable:app-deploy:v0.0.1").Return(stringifiedValues, true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, values := svc.GetValues(context.Background(), "stable", "app-deploy", "v0.0.1")
 	assert.NoError(t, err)
 
 	expectedValues := map[string]interface{}{
@@ -73,14 +213,36 @@ func TestService_GetValuesFromCache(t *testing.T) {
 	assert.Equal(t, expectedValues, values)
 }
 
+func TestService_GetValuesStorageError(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "value:stable:app-deploy:v0.0.1").Return("", false, errors.New("connection refused"))
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, _ := svc.GetValues(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.EqualError(t, err, "connection refused")
+
+	helm.AssertNotCalled(t, "GetValues", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_GetStringifiedManifestsNotFound(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "manifests:stable:app-deploy:v0.0.1:hash").Return("", false, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	_, err := svc.GetStringifiedManifests(context.Background(), "stable", "app-deploy", "v0.0.1", "hash")
+
+	assert.True(t, errors.Is(err, service.ErrNotFound))
+}
+
 func TestService_GetTemplatesFromCache(t *testing.T) {
 	stringifiedTemplates := "[{\"name\":\"deployment.yaml\",\"content\":\"kind: Deployment\"}]"
 
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	repository.On("Get", "template-stable-app-deploy-v0.0.1").Return(stringifiedTemplates)
-	svc := service.NewService(helm, repository)
-	templates := svc.GetTemplates("stable", "app-deploy", "v0.0.1")
+	repository.On("Get", mock.Anything, "template:stable:app-deploy:v0.0.1").Return(stringifiedTemplates, true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	templates, err := svc.GetTemplates(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.NoError(t, err)
 
 	expectedTemplates := []model.Template{
 		{
@@ -92,14 +254,267 @@ func TestService_GetTemplatesFromCache(t *testing.T) {
 	assert.Equal(t, expectedTemplates, templates)
 }
 
+func TestService_DiffTemplates(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "template:stable:app-deploy:v0.0.1").Return("[{\"name\":\"deployment.yaml\",\"content\":\"replicas: 1\\n\"}]", true, nil)
+	repository.On("Get", mock.Anything, "template:stable:app-deploy:v0.0.2").Return("[{\"name\":\"deployment.yaml\",\"content\":\"replicas: 2\\n\"}]", true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	templateDiff, err := svc.DiffTemplates(context.Background(), "stable", "app-deploy", "v0.0.1", "v0.0.2")
+	assert.NoError(t, err)
+
+	expectedDiff := model.TemplateDiffResponse{
+		From: "v0.0.1",
+		To:   "v0.0.2",
+		Files: []model.FileDiff{
+			{
+				Name:   "deployment.yaml",
+				Status: model.DiffModified,
+				Diff:   "--- v0.0.1/deployment.yaml\n+++ v0.0.2/deployment.yaml\n@@ -1 +1 @@\n-replicas: 1\n+replicas: 2\n",
+			},
+		},
+	}
+	assert.Equal(t, expectedDiff, templateDiff)
+}
+
+func TestService_GetChartMetadata(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	metadata := model.ChartMetadata{
+		Name:        "app-deploy",
+		Version:     "v0.0.1",
+		APIVersion:  "v2",
+		Type:        "application",
+		KubeVersion: ">=1.16.0-0",
+		Deprecated:  true,
+	}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "metadata:stable:app-deploy:v0.0.1").Return("", false, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Set", mock.Anything, "metadata:stable:app-deploy:v0.0.1", mock.Anything, time.Duration(0)).Return(nil)
+	helm.On("GetChartMetadata", mock.Anything, "app-deploy", "v0.0.1").Return(metadata, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	actual, err := svc.GetChartMetadata(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, actual)
+	repository.AssertExpectations(t)
+	helm.AssertExpectations(t)
+}
+
+func TestService_GetDependencies(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	dependencies := []model.Subchart{
+		{
+			Name:        "redis",
+			Version:     "10.5.7",
+			Condition:   "redis.enabled",
+			Alias:       "cache",
+			ValuesScope: "cache",
+			Templates:   []model.Template{{Name: "templates/redis.yaml", Content: "apiVersion: apps/v1"}},
+		},
+	}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "dependencies:stable:app-deploy:v0.0.1").Return("", false, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Set", mock.Anything, "dependencies:stable:app-deploy:v0.0.1", mock.Anything, time.Duration(0)).Return(nil)
+	helm.On("GetDependencies", mock.Anything, "app-deploy", "v0.0.1").Return(dependencies, nil).Once()
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	actual, err := svc.GetDependencies(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, dependencies, actual)
+	repository.AssertExpectations(t)
+	helm.AssertExpectations(t)
+}
+
+func TestService_AnalyzeSubcharts(t *testing.T) {
+	apiVersions := "[{\"kube_version\":\"1.16\",\"api_versions\":[\"apps/v1\",\"v1\"]}]"
+	subcharts := []model.Subchart{
+		{
+			Name:        "sample",
+			ValuesScope: "web",
+			Templates:   []model.Template{{Name: "templates/ingress.yaml", Content: "apiVersion: extensions/v1beta1"}},
+			Dependencies: []model.Subchart{
+				{
+					Name:        "redis",
+					ValuesScope: "web.redis",
+					Templates:   []model.Template{{Name: "templates/redis.yaml", Content: "apiVersion: apps/v1"}},
+				},
+			},
+		},
+	}
+
+	repository := new(repoMock.Repository)
+	repository.On("Keys", mock.Anything, "kube-version:").Return([]string{}, nil)
+	repository.On("Get", mock.Anything, "api-versions").Return(apiVersions, true, nil)
+	svc := service.NewService(new(helmMock.Helm), repository, analyzer.New(), service.CacheTTL{})
+
+	results, err := svc.AnalyzeSubcharts(context.Background(), subcharts, "1.16")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AnalyticsResult{
+		{Template: subcharts[0].Templates[0], Compatible: false, Subchart: "web"},
+		{Template: subcharts[0].Dependencies[0].Templates[0], Compatible: true, Subchart: "web.redis"},
+	}, results)
+}
+
+func TestService_GetValuesSchema(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	schema := `{"type":"object"}`
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "schema:stable:app-deploy:v0.0.1").Return("", false, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Set", mock.Anything, "schema:stable:app-deploy:v0.0.1", schema, time.Duration(0)).Return(nil)
+	helm.On("GetValuesSchema", mock.Anything, "app-deploy", "v0.0.1").Return([]byte(schema), nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	valuesSchema, err := svc.GetValuesSchema(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, schema, string(valuesSchema))
+	repository.AssertExpectations(t)
+}
+
+func TestService_GetValuesSchemaWithoutSchema(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "schema:stable:app-deploy:v0.0.1").Return("null", true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	_, err := svc.GetValuesSchema(context.Background(), "stable", "app-deploy", "v0.0.1")
+	assert.True(t, errors.Is(err, service.ErrNotFound))
+	helm.AssertNotCalled(t, "GetValuesSchema", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ValidateValues(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	valuesOptions := model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: two"}}
+	schemaErrors := []model.SchemaError{{Path: "$.replicaCount", Type: "invalid_type", Message: "Invalid type. Expected: integer, given: string"}}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	helm.On("ValidateValues", mock.Anything, "app-deploy", "v0.0.1", valuesOptions).Return(schemaErrors, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	validation, err := svc.ValidateValues(context.Background(), "stable", "app-deploy", "v0.0.1", valuesOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ValuesValidationResponse{Valid: false, Errors: schemaErrors}, validation)
+}
+
+func TestService_DiffValues(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "value:stable:app-deploy:v0.0.1").Return("{\"replicaCount\":1,\"image\":{\"tag\":\"1.16\"}}", true, nil)
+	repository.On("Get", mock.Anything, "value:stable:app-deploy:v0.0.2").Return("{\"replicaCount\":2,\"service\":{\"port\":80}}", true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	valuesDiff, err := svc.DiffValues(context.Background(), "stable", "app-deploy", "v0.0.1", "v0.0.2")
+	assert.NoError(t, err)
+
+	expectedDiff := model.ValuesDiffResponse{
+		From: "v0.0.1",
+		To:   "v0.0.2",
+		Changes: []model.ValueChange{
+			{Path: "image.tag", Status: model.DiffRemoved, Old: "1.16"},
+			{Path: "replicaCount", Status: model.DiffChanged, Old: float64(1), New: float64(2)},
+			{Path: "service.port", Status: model.DiffAdded, New: float64(80)},
+		},
+	}
+	assert.Equal(t, expectedDiff, valuesDiff)
+}
+
+func TestService_DiffManifests(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 3"}}}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Get", mock.Anything, mock.Anything).Return("", false, nil)
+	repository.On("Set", mock.Anything, mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", renderOptions).
+		Return(nil, []model.Manifest{{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 2\n"}})
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.2", renderOptions).
+		Return(nil, []model.Manifest{{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\nspec:\n  replicas: 3\n"}})
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	manifestDiff, err := svc.DiffManifests(context.Background(), "stable", "app-deploy", "v0.0.1", "v0.0.2", renderOptions)
+	assert.NoError(t, err)
+
+	expectedDiff := model.ManifestDiffResponse{
+		From: "v0.0.1",
+		To:   "v0.0.2",
+		Resources: []model.ResourceDiff{
+			{
+				Resource: model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"},
+				Status:   model.DiffModified,
+				Changes:  []model.FieldChange{{Path: "$.spec.replicas", Status: model.DiffChanged, Old: 2, New: 3}},
+			},
+		},
+	}
+	assert.Equal(t, expectedDiff, manifestDiff)
+	helm.AssertExpectations(t)
+}
+
+func TestService_AnalyzeManifestsWithCapabilities(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	apiVersions := "[{\"kube_version\":\"1.16\",\"api_versions\":[\"apps/v1\",\"v1\"]}]"
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 3"}}}
+	capabilitiesOptions := renderOptions
+	capabilitiesOptions.KubeVersion = "1.16"
+	capabilitiesOptions.APIVersions = []string{"apps/v1", "v1"}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	deprecations := "[{\"group\":\"extensions\",\"version\":\"v1beta1\",\"kind\":\"Ingress\",\"deprecated_in\":\"1.14\",\"removed_in\":\"1.22\",\"replacement\":\"networking.k8s.io/v1\"}]"
+	repository.On("Keys", mock.Anything, "kube-version:").Return([]string{}, nil)
+	repository.On("Get", mock.Anything, "api-versions").Return(apiVersions, true, nil)
+	repository.On("Get", mock.Anything, "api-deprecations").Return(deprecations, true, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Get", mock.Anything, mock.Anything).Return("", false, nil)
+	repository.On("Set", mock.Anything, mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", capabilitiesOptions).
+		Return(nil, []model.Manifest{{Name: "deployment.yaml", Content: "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n---\napiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: app\n"}}).Once()
+	svc := service.NewService(helm, repository, analyzer.New(), service.CacheTTL{})
+
+	analysis, err := svc.AnalyzeManifests(context.Background(), "stable", "app-deploy", "v0.0.1", renderOptions, "1.16", true)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ManifestAnalysisResponse{
+		KubeVersion: "1.16",
+		Resources: []model.ResourceAnalysis{
+			{Manifest: "deployment.yaml", Resource: model.ResourceID{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"}, Compatible: true},
+			{Manifest: "deployment.yaml", Resource: model.ResourceID{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app"}, Compatible: false},
+		},
+		Deprecations: []model.DeprecationFinding{
+			{
+				Manifest:     "deployment.yaml",
+				Resource:     model.ResourceID{APIVersion: "extensions/v1beta1", Kind: "Ingress", Name: "app"},
+				Status:       model.APIDeprecated,
+				DeprecatedIn: "1.14",
+				RemovedIn:    "1.22",
+				Replacement:  "networking.k8s.io/v1",
+				Message:      "extensions/v1beta1 Ingress deprecated in 1.14, removed in 1.22, use networking.k8s.io/v1",
+			},
+		},
+	}, analysis)
+	helm.AssertExpectations(t)
+}
+
 func TestService_GetStringifiedManifestsFromCache(t *testing.T) {
 	stringifiedManifest := "{\"url\":\"http://chart-viewer.com\",\"manifests\":[{\"name\":\"deployment.yaml\",\"content\":\"kind: Deployment\"}]}"
 
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-hash").Return(stringifiedManifest)
-	svc := service.NewService(helm, repository)
-	manifest := svc.GetStringifiedManifests("stable", "app-deploy", "v0.0.1", "hash")
+	repository.On("Get", mock.Anything, "manifests:stable:app-deploy:v0.0.1:hash").Return(stringifiedManifest, true, nil)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	manifest, err := svc.GetStringifiedManifests(context.Background(), "stable", "app-deploy", "v0.0.1", "hash")
+	assert.NoError(t, err)
 
 	expectedManifests := "---\nkind: Deployment\n"
 
@@ -107,10 +522,10 @@ func TestService_GetStringifiedManifestsFromCache(t *testing.T) {
 }
 
 func TestService_RenderManifest(t *testing.T) {
-	createValuesTestFile()
-	hash := getValuesHash()
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"affinity: {}"}}}
+	hash := getValuesHash("affinity: {}")
 
-	repos := "[{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}]"
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
 	rawManifest := "{\"url\":\"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/e554acfce37f759ada1b70240cee4bcf\",\"manifests\":[{\"name\":\"deployment.yaml\",\"content\":\"kind: Deployment\"}]}"
 	manifest := []model.Manifest{
 		{
@@ -122,13 +537,13 @@ func TestService_RenderManifest(t *testing.T) {
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
 
-	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-"+hash).Return("")
-	repository.On("Get", "repos").Return(repos)
-	repository.On("Set", "manifests-stable-app-deploy-v0.0.1-"+hash, rawManifest)
-	helm.On("RenderManifest", "https://charts.helm.sh/stable", "app-deploy", "v0.0.1", []string{"/tmp/values.yaml"}).Return(nil, manifest)
+	repository.On("Get", mock.Anything, "manifests:stable:app-deploy:v0.0.1:"+hash).Return("", false, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Set", mock.Anything, "manifests:stable:app-deploy:v0.0.1:"+hash, rawManifest, time.Duration(0)).Return(nil)
+	helm.On("RenderManifest", model.Repo{Name: "stable", URL: "https://charts.helm.sh/stable"}, "app-deploy", "v0.0.1", renderOptions).Return(nil, manifest)
 
-	svc := service.NewService(helm, repository)
-	err, actualManifest := svc.RenderManifest("stable", "app-deploy", "v0.0.1", []string{"/tmp/values.yaml"})
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, actualManifest := svc.RenderManifest(context.Background(), "stable", "app-deploy", "v0.0.1", renderOptions)
 	assert.NoError(t, err)
 
 	expectedManifests := model.ManifestResponse{
@@ -145,8 +560,8 @@ func TestService_RenderManifest(t *testing.T) {
 }
 
 func TestService_RenderManifest_Cached(t *testing.T) {
-	createValuesTestFile()
-	hash := getValuesHash()
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"affinity: {}"}}}
+	hash := getValuesHash("affinity: {}")
 
 	stringifiedManifest := "{\"url\":\"/api/v1/charts/manifests/stable/app-deploy/v0.0.1/" + hash + "\",\"manifests\":[{\"name\":\"deployment.yaml\",\"content\":\"kind: Deployment\"}]}"
 	manifest := []model.Manifest{
@@ -158,11 +573,11 @@ func TestService_RenderManifest_Cached(t *testing.T) {
 
 	repository := new(repoMock.Repository)
 	helm := new(helmMock.Helm)
-	repository.On("Get", "manifests-stable-app-deploy-v0.0.1-"+hash).Return(stringifiedManifest)
-	helm.On("RenderManifest", "https://charts.helm.sh/stable", "app-deploy", "v0.0.1", []string{"/tmp/values.yaml"}).Return(nil, manifest)
+	repository.On("Get", mock.Anything, "manifests:stable:app-deploy:v0.0.1:"+hash).Return(stringifiedManifest, true, nil)
+	helm.On("RenderManifest", model.Repo{Name: "stable", URL: "https://charts.helm.sh/stable"}, "app-deploy", "v0.0.1", renderOptions).Return(nil, manifest)
 
-	svc := service.NewService(helm, repository)
-	err, actualManifest := svc.RenderManifest("stable", "app-deploy", "v0.0.1", []string{"/tmp/values.yaml"})
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, actualManifest := svc.RenderManifest(context.Background(), "stable", "app-deploy", "v0.0.1", renderOptions)
 	assert.NoError(t, err)
 
 	expectedManifests := model.ManifestResponse{
@@ -178,14 +593,381 @@ func TestService_RenderManifest_Cached(t *testing.T) {
 	assert.Equal(t, expectedManifests, actualManifest)
 }
 
-func getValuesHash() string {
-	valuesFileContent, _ := ioutil.ReadFile("/tmp/values.yaml")
-	hash := md5.Sum(valuesFileContent)
+func TestService_RenderManifest_CacheTTL(t *testing.T) {
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"affinity: {}"}}}
+	hash := getValuesHash("affinity: {}")
+
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	manifest := []model.Manifest{
+		{
+			Name:    "deployment.yaml",
+			Content: "kind: Deployment",
+		},
+	}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "manifests:stable:app-deploy:v0.0.1:"+hash).Return("", false, nil)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Set", mock.Anything, "manifests:stable:app-deploy:v0.0.1:"+hash, mock.Anything, 24*time.Hour).Return(nil).Once()
+	helm.On("RenderManifest", model.Repo{Name: "stable", URL: "https://charts.helm.sh/stable"}, "app-deploy", "v0.0.1", renderOptions).Return(nil, manifest)
+
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{Manifests: 24 * time.Hour})
+	err, _ := svc.RenderManifest(context.Background(), "stable", "app-deploy", "v0.0.1", renderOptions)
+	assert.NoError(t, err)
+
+	repository.AssertExpectations(t)
+}
+
+func TestService_PurgeCacheOfChart(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Keys", mock.Anything, "value:stable:app-deploy:").Return([]string{"value:stable:app-deploy:v0.0.1"}, nil).Once()
+	repository.On("Keys", mock.Anything, "template:stable:app-deploy:").Return([]string{"template:stable:app-deploy:v0.0.1", "template:stable:app-deploy:v0.0.2"}, nil).Once()
+	repository.On("Keys", mock.Anything, "manifests:stable:app-deploy:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "schema:stable:app-deploy:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "metadata:stable:app-deploy:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "dependencies:stable:app-deploy:").Return([]string{}, nil).Once()
+	repository.On("Delete", mock.Anything, "value:stable:app-deploy:v0.0.1").Return(nil).Once()
+	repository.On("Delete", mock.Anything, "template:stable:app-deploy:v0.0.1").Return(nil).Once()
+	repository.On("Delete", mock.Anything, "template:stable:app-deploy:v0.0.2").Return(nil).Once()
+
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	deleted, err := svc.PurgeCache(context.Background(), "stable", "app-deploy")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	repository.AssertExpectations(t)
+}
+
+func TestService_PurgeCacheOfRepo(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Exists", mock.Anything, "charts:stable").Return(true, nil).Once()
+	repository.On("Keys", mock.Anything, "value:stable:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "template:stable:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "manifests:stable:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "schema:stable:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "metadata:stable:").Return([]string{}, nil).Once()
+	repository.On("Keys", mock.Anything, "dependencies:stable:").Return([]string{}, nil).Once()
+	repository.On("Delete", mock.Anything, "charts:stable").Return(nil).Once()
+
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	deleted, err := svc.PurgeCache(context.Background(), "stable", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	repository.AssertExpectations(t)
+}
+
+func TestService_PurgeCacheOfRepoSharingPrefix(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	for _, key := range []string{"charts:stable", "charts:stable-internal", "value:stable:nginx:0.1.0", "value:stable-internal:nginx:0.1.0"} {
+		assert.NoError(t, storage.Set(ctx, key, "{}", 0))
+	}
+
+	svc := service.NewService(new(helmMock.Helm), storage, nil, service.CacheTTL{})
+	deleted, err := svc.PurgeCache(ctx, "stable", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err := storage.Keys(ctx, "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"charts:stable-internal", "value:stable-internal:nginx:0.1.0"}, keys)
+}
+
+func TestService_DeleteLegacyCache(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	for key, value := range map[string]string{
+		"repo:stable":                       "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}",
+		"stable":                            "[]",
+		"value-stable-nginx-0.1.0":          "{}",
+		"template-stable-nginx-0.1.0":       "[]",
+		"manifests-stable-nginx-0.1.0-9f86": "{}",
+		"charts:stable":                     "[]",
+		"value:stable:nginx:0.1.0":          "{}",
+		"api-deprecations":                  "[]",
+	} {
+		assert.NoError(t, storage.Set(ctx, key, value, 0))
+	}
+
+	svc := service.NewService(new(helmMock.Helm), storage, nil, service.CacheTTL{})
+	deleted, err := svc.DeleteLegacyCache(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, deleted)
+
+	keys, err := storage.Keys(ctx, "")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"repo:stable", "charts:stable", "value:stable:nginx:0.1.0", "api-deprecations"}, keys)
+}
+
+func TestService_UploadChart(t *testing.T) {
+	archive := []byte("chart archive")
+	uploadedChart := model.UploadedChart{ID: "2c26b46b", Repo: "uploads", Name: "sample", Version: "0.1.0"}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	helm.On("UploadChart", archive).Return(uploadedChart, nil).Once()
+	repository.On("Delete", mock.Anything, "charts:uploads").Return(nil).Once()
+
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	actual, err := svc.UploadChart(context.Background(), archive)
+	assert.NoError(t, err)
+	assert.Equal(t, uploadedChart, actual)
+
+	repository.AssertExpectations(t)
+}
+
+func TestService_GetUploadedChartValues(t *testing.T) {
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	uploadsRepo := model.Repo{Name: "uploads", Type: "upload"}
+	repository.On("Get", mock.Anything, "value:uploads:2c26b46b:0.1.0").Return("", false, nil)
+	repository.On("Set", mock.Anything, "value:uploads:2c26b46b:0.1.0", "{\"replicaCount\":1}", time.Duration(0)).Return(nil)
+	helm.On("GetValues", uploadsRepo, "2c26b46b", "0.1.0").Return(nil, map[string]interface{}{"replicaCount": 1}).Once()
+
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+	err, values := svc.GetValues(context.Background(), "uploads", "2c26b46b", "0.1.0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicaCount": 1}, values)
+}
+
+func TestService_RenderManifest_HashesEveryInput(t *testing.T) {
+	repo := "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}"
+	manifest := []model.Manifest{
+		{
+			Name:    "deployment.yaml",
+			Content: "kind: Deployment",
+		},
+	}
+
+	repository := new(repoMock.Repository)
+	helm := new(helmMock.Helm)
+	repository.On("Get", mock.Anything, "repo:stable").Return(repo, true, nil)
+	repository.On("Get", mock.Anything, mock.Anything).Return("", false, nil)
+	repository.On("Set", mock.Anything, mock.Anything, mock.Anything, time.Duration(0)).Return(nil)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", mock.Anything).Return(nil, manifest)
+	svc := service.NewService(helm, repository, nil, service.CacheTTL{})
+
+	render := func(valuesOptions model.ValuesOptions) string {
+		err, response := svc.RenderManifest(context.Background(), "stable", "app-deploy", "v0.0.1", model.RenderOptions{ValuesOptions: valuesOptions})
+		assert.NoError(t, err)
+		return response.URL
+	}
+
+	base := render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}})
+	assert.NotEqual(t, base, render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 2", "replicaCount: 1"}}))
+	assert.NotEqual(t, base, render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}, Set: []string{"image.tag=1.17"}}))
+	assert.NotEqual(t, base, render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}, SetString: []string{"image.tag=1.17"}}))
+	assert.NotEqual(t, base, render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}, SetFile: map[string]string{"config": "a"}}))
+	assert.Equal(t, base, render(model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}}))
+
+	err, response := svc.RenderManifest(context.Background(), "stable", "app-deploy", "v0.0.1", model.RenderOptions{
+		ValuesOptions: model.ValuesOptions{Values: model.ValuesDocuments{"replicaCount: 1", "replicaCount: 2"}},
+		KubeVersion:   "1.21",
+	})
+	assert.NoError(t, err)
+	assert.NotEqual(t, base, response.URL)
+}
+
+func getValuesHash(values string) string {
+	hash := md5.Sum([]byte(values))
 	return fmt.Sprintf("%x", hash)
 }
 
-func createValuesTestFile() {
-	valueBytes := []byte("affinity: {}")
-	fileLocation := "/tmp/values.yaml"
-	_ = ioutil.WriteFile(fileLocation, valueBytes, 0644)
+func TestService_KubeVersionsFromLegacyList(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	err := storage.Set(context.Background(), "api-versions", "[{\"kube_version\":\"1.21\",\"api_versions\":[\"v1\"]},{\"kube_version\":\"1.9\",\"api_versions\":[\"v1\"]}]", 0)
+	assert.NoError(t, err)
+	svc := service.NewService(nil, storage, nil, service.CacheTTL{})
+
+	kubeVersion, err := svc.GetKubeVersion(context.Background(), "v1.21.3")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.21", kubeVersion.KubeVersion)
+
+	err = svc.AddKubeVersion(context.Background(), model.KubernetesAPIVersion{KubeVersion: "prod-cluster-1.24", APIVersions: []string{"v1", "apps/v1"}})
+	assert.NoError(t, err)
+
+	kubeVersions, err := svc.GetKubeVersions(context.Background())
+	assert.NoError(t, err)
+	var names []string
+	for _, k := range kubeVersions {
+		names = append(names, k.KubeVersion)
+	}
+	assert.Equal(t, []string{"1.9", "1.21", "prod-cluster-1.24"}, names)
+}
+
+func TestService_DeleteMigratedKubeVersions(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	err := storage.Set(ctx, "api-versions", "[{\"kube_version\":\"1.21\",\"api_versions\":[\"v1\"]}]", 0)
+	assert.NoError(t, err)
+	svc := service.NewService(nil, storage, nil, service.CacheTTL{})
+
+	assert.NoError(t, svc.DeleteKubeVersion(ctx, "1.21"))
+
+	kubeVersions, err := svc.GetKubeVersions(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, kubeVersions)
+	exists, err := storage.Exists(ctx, "api-versions")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestService_KubeVersionCRUD(t *testing.T) {
+	svc := service.NewService(nil, repository.NewMemoryRepository(), nil, service.CacheTTL{})
+	ctx := context.Background()
+	kubeVersion := model.KubernetesAPIVersion{
+		KubeVersion: "1.22",
+		APIVersions: []string{"apps/v1", "v1"},
+		Kinds:       map[string][]string{"apps/v1": {"Deployment"}},
+	}
+
+	assert.NoError(t, svc.AddKubeVersion(ctx, kubeVersion))
+	assert.True(t, errors.Is(svc.AddKubeVersion(ctx, kubeVersion), service.ErrKubeVersionExists))
+
+	kubeVersion.APIVersions = append(kubeVersion.APIVersions, "batch/v1")
+	assert.NoError(t, svc.UpdateKubeVersion(ctx, kubeVersion))
+	stored, err := svc.GetKubeVersion(ctx, "1.22.4")
+	assert.NoError(t, err)
+	assert.Equal(t, kubeVersion, stored)
+
+	assert.NoError(t, svc.DeleteKubeVersion(ctx, "1.22"))
+	assert.True(t, errors.Is(svc.DeleteKubeVersion(ctx, "1.22"), service.ErrNotFound))
+	assert.True(t, errors.Is(svc.UpdateKubeVersion(ctx, kubeVersion), service.ErrNotFound))
+
+	_, err = svc.GetKubeVersion(ctx, "1.22")
+	var unknownKubeVersion *service.UnknownKubeVersionError
+	assert.True(t, errors.As(err, &unknownKubeVersion))
+	assert.Equal(t, []string{}, unknownKubeVersion.Supported)
+}
+
+func TestService_AddKubeVersionInvalid(t *testing.T) {
+	svc := service.NewService(nil, repository.NewMemoryRepository(), nil, service.CacheTTL{})
+
+	for _, kubeVersion := range []model.KubernetesAPIVersion{
+		{APIVersions: []string{"v1"}},
+		{KubeVersion: "prod:1.24", APIVersions: []string{"v1"}},
+		{KubeVersion: "1.24"},
+		{KubeVersion: "1.24", APIVersions: []string{"v1"}, Kinds: map[string][]string{"apps/v1": {"Deployment"}}},
+	} {
+		err := svc.AddKubeVersion(context.Background(), kubeVersion)
+		assert.True(t, errors.Is(err, service.ErrInvalidKubeVersion), kubeVersion.KubeVersion)
+	}
+}
+
+func TestService_AnalyzeManifestsForClusterKubeVersion(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repo:stable", "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}", 0))
+
+	helm := new(helmMock.Helm)
+	svc := service.NewService(helm, storage, analyzer.New(), service.CacheTTL{})
+	assert.NoError(t, svc.ImportAPIDeprecations(ctx, []model.APIDeprecation{
+		{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget", DeprecatedIn: "1.21", RemovedIn: "1.25", Replacement: "policy/v1"},
+	}))
+	assert.NoError(t, svc.AddKubeVersion(ctx, model.KubernetesAPIVersion{
+		KubeVersion: "prod-cluster-1.24",
+		Release:     "1.24",
+		APIVersions: []string{"monitoring.coreos.com/v1", "policy/v1beta1", "v1"},
+	}))
+
+	renderOptions := model.RenderOptions{KubeVersion: "1.24", APIVersions: []string{"monitoring.coreos.com/v1", "policy/v1beta1", "v1"}}
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", renderOptions).
+		Return(nil, []model.Manifest{{Name: "pdb.yaml", Content: "apiVersion: policy/v1beta1\nkind: PodDisruptionBudget\nmetadata:\n  name: app\n"}}).Once()
+
+	analysis, err := svc.AnalyzeManifests(ctx, "stable", "app-deploy", "v0.0.1", model.RenderOptions{}, "prod-cluster-1.24", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-cluster-1.24", analysis.KubeVersion)
+	assert.True(t, analysis.Resources[0].Compatible)
+	assert.Equal(t, "policy/v1beta1 PodDisruptionBudget deprecated in 1.21, removed in 1.25, use policy/v1", analysis.Deprecations[0].Message)
+	helm.AssertExpectations(t)
+}
+
+func TestService_CheckPolicies(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repo:stable", "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}", 0))
+
+	helm := new(helmMock.Helm)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", model.RenderOptions{}).
+		Return(nil, []model.Manifest{{Name: "service.yaml", Content: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: NodePort\n"}}).Once()
+	svc := service.NewService(helm, storage, analyzer.New(), service.CacheTTL{})
+
+	report, err := svc.CheckPolicies(ctx, "stable", "app-deploy", "v0.0.1", model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.PolicyCheckResponse{
+		Findings: []model.PolicyFinding{
+			{
+				Rule:     "node-port-service",
+				Severity: model.SeverityMedium,
+				Manifest: "service.yaml",
+				Resource: model.ResourceID{APIVersion: "v1", Kind: "Service", Name: "app"},
+				Path:     "$.spec.type",
+				Message:  "service exposes its ports on every node",
+			},
+		},
+	}, report)
+	helm.AssertExpectations(t)
+}
+
+func TestService_GetImages(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repo:stable", "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}", 0))
+
+	renderOptions := model.RenderOptions{ValuesOptions: model.ValuesOptions{Set: []string{"image.tag=1.25"}}}
+	helm := new(helmMock.Helm)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", renderOptions).
+		Return(nil, []model.Manifest{{Name: "job.yaml", Content: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\nspec:\n  template:\n    spec:\n      containers:\n        - name: migrate\n          image: nginx:1.25\n"}}).Once()
+	svc := service.NewService(helm, storage, analyzer.New(), service.CacheTTL{})
+
+	inventory, err := svc.GetImages(ctx, "stable", "app-deploy", "v0.0.1", renderOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ImageInventoryResponse{
+		Images: []model.ContainerImage{
+			{
+				ImageReference: model.ImageReference{Image: "nginx:1.25", Registry: "docker.io", Repository: "library/nginx", Tag: "1.25"},
+				Manifest:       "job.yaml",
+				Resource:       model.ResourceID{APIVersion: "batch/v1", Kind: "Job", Name: "migrate"},
+				Container:      "migrate",
+				ContainerType:  "containers",
+			},
+		},
+	}, inventory)
+	helm.AssertExpectations(t)
+}
+
+func TestService_DiffRBAC(t *testing.T) {
+	storage := repository.NewMemoryRepository()
+	ctx := context.Background()
+	assert.NoError(t, storage.Set(ctx, "repo:stable", "{\"name\":\"stable\",\"url\":\"https://charts.helm.sh/stable\"}", 0))
+
+	role := "apiVersion: rbac.authorization.k8s.io/v1\nkind: Role\nmetadata:\n  name: app\nrules:\n  - apiGroups: [\"\"]\n    resources: [\"configmaps\"]\n    verbs: [%s]\n"
+	helm := new(helmMock.Helm)
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.1", model.RenderOptions{}).
+		Return(nil, []model.Manifest{{Name: "role.yaml", Content: fmt.Sprintf(role, `"get"`)}}).Once()
+	helm.On("RenderManifest", mock.Anything, "app-deploy", "v0.0.2", model.RenderOptions{}).
+		Return(nil, []model.Manifest{{Name: "role.yaml", Content: fmt.Sprintf(role, `"get", "*"`)}}).Once()
+	svc := service.NewService(helm, storage, analyzer.New(), service.CacheTTL{})
+
+	rbacDiff, err := svc.DiffRBAC(ctx, "stable", "app-deploy", "v0.0.1", "v0.0.2", model.RenderOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, model.RBACDiffResponse{
+		From: "v0.0.1",
+		To:   "v0.0.2",
+		Changes: []model.RBACPermissionChange{
+			{
+				Status:     model.DiffModified,
+				Permission: model.RBACPermission{Scope: "default", Resource: "configmaps", Verbs: []string{"*", "get"}, Role: "Role default/app", Wildcard: true},
+				AddedVerbs: []string{"*"},
+			},
+		},
+		Findings: []model.RBACFinding{
+			{Severity: model.SeverityMedium, Role: "Role default/app", Scope: "default", Message: "Role default/app grants *,get on configmaps in namespace default"},
+		},
+	}, rbacDiff)
+	helm.AssertExpectations(t)
 }